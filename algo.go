@@ -5,64 +5,19 @@ import (
 	"sort"
 )
 
-// JacobiSVD computes the Jacobi SVD of A, returning U, Sigma, V^T
+// JacobiSVD computes the singular values of A using one-sided Jacobi rotations
+// and returns them in ascending order. One value is returned per column of A,
+// so a matrix with more columns than rows has leading zero values.
+// A is not modified. Use the SVD type to obtain the singular vectors U and V.
 func JacobiSVD(A *DenseM) (sigma *DenseV) {
-	const tol = 1e-14
-	var rots int = 1
-	nrow, ncol := A.Dims()
-	fsigma := make([]float64, ncol)
-	// populate sigma with the columnwise squared sum of A
-	for i := 0; i < nrow; i++ {
-		for j := 0; j < ncol; j++ {
-			v := A.At(i, j)
-			fsigma[j] += v * v
-		}
-	}
-	// populate V as the identity matrix
-	G := NewDenseMatrix(2, 2, nil)
-	V := NewDenseMatrix(nrow, nrow, nil)
-	V.Copy(Eye(nrow))
-	var i, j int
-	tolsigma := tol * Norm(A, 2)
-	Aux := NewDenseMatrix(ncol, 2, nil)
-	for rots >= 1 {
-		i++
-		rots = 0
-		for p := 0; p < ncol; p++ {
-			colp := A.ColView(p)
-			for q := p + 1; q < ncol; q++ {
-				sp := fsigma[p]
-				sq := fsigma[q]
-				spq := sp * sq
-				colq := A.ColView(q)
-				beta := Dot(colp, colq)
-				if spq > tolsigma && math.Abs(beta) >= tol*math.Sqrt(spq) {
-					j++
-					rots++
-					t := G.jacobi(sp, beta, sq)
-					fsigma[p] = sp - beta*t
-					fsigma[q] = sq + beta*t
-					Aux.Mul(Slice(A, nil, []int{p, q}), G)
-					colp.CopyVec(Aux.ColView(0))
-					colq.CopyVec(Aux.ColView(1))
-				}
-			}
-		}
-	}
-
-	// Post Processing
-	sort.Float64s(fsigma)
-	for k := 0; k < ncol; k++ {
-		s := fsigma[k]
-		if s == 0 {
-			for i := k; i < len(fsigma); i++ {
-				fsigma[i] = 0
-			}
-			break
-		}
-		fsigma[k] = math.Sqrt(s)
-	}
-	return NewDenseVector(ncol, fsigma)
+	var svd SVD
+	svd.Factorize(A, SVDNone)
+	r, c := A.Dims()
+	s := make([]float64, c)
+	// The values beyond the first min(r, c) are zero.
+	svd.Values(s[c-min(r, c):])
+	sort.Float64s(s)
+	return NewDenseVector(c, s)
 }
 
 // The Jacobi rotation is a plane unitary similarity transformation:
@@ -75,19 +30,7 @@ func (G *DenseM) jacobi(alpha, beta, gamma float64) (t float64) {
 	if r, c := G.Dims(); r != 2 && c != 2 {
 		panic(ErrDim)
 	}
-	var c, s float64
-	if beta != 0 {
-		tau := (gamma - alpha) / (2 * beta)
-		if tau >= 0 {
-			t = 1 / (tau + math.Sqrt(1+tau*tau))
-		} else {
-			t = -1 / (-tau + math.Sqrt(1+tau*tau))
-		}
-		c = 1 / math.Sqrt(1+t*t)
-		s = t * c
-	} else {
-		c = 1
-	}
+	c, s, t := jacobiRotation(alpha, beta, gamma)
 	G.Set(0, 0, c)
 	G.Set(0, 1, s)
 	G.Set(1, 0, -s)
@@ -95,11 +38,32 @@ func (G *DenseM) jacobi(alpha, beta, gamma float64) (t float64) {
 	return t
 }
 
-// MatInvertSquare inverts square matrix A of dimension nxn, storing the result in out.
+// jacobiRotation returns the cosine, sine and tangent of the Jacobi rotation
+// G that diagonalizes the symmetric matrix [alpha, beta; beta, gamma].
+func jacobiRotation[T Float](alpha, beta, gamma T) (c, s, t T) {
+	if beta == 0 {
+		return 1, 0, 0
+	}
+	tau := (gamma - alpha) / (2 * beta)
+	if tau >= 0 {
+		t = 1 / (tau + sqrtOf(1+tau*tau))
+	} else {
+		t = -1 / (-tau + sqrtOf(1+tau*tau))
+	}
+	c = 1 / sqrtOf(1+t*t)
+	s = t * c
+	return c, s, t
+}
+
+// Inverse inverts square matrix A of dimension nxn, storing the result in out.
 // scratch must be n x 2n or nil in which case the slice is allocated temporarily.
+// If out is not initialized it is allocated automatically.
 //
-// Gauss-Jordan elimination is used to perform the inversion, A must be non-singular.
-func (out *DenseM) invertSquare(A Matrix, scratchSlice []float64) error {
+// Gauss-Jordan elimination with partial pivoting is used to perform the inversion.
+// If A is singular or too ill-conditioned for the inverse to be trusted a
+// ConditionError holding the reciprocal condition number of A is returned. The
+// inverse is still stored in out unless A is exactly singular.
+func (out *DenseM) Inverse(A Matrix, scratchSlice []float64) error {
 	n, c := A.Dims()
 	if n != c {
 		return ErrDim
@@ -108,68 +72,77 @@ func (out *DenseM) invertSquare(A Matrix, scratchSlice []float64) error {
 	if out.data == nil {
 		*out = *NewDenseMatrix(n, n, nil)
 	}
-	var scratchZeroed bool
+	if r, c := out.Dims(); r != n || c != n {
+		return ErrDim
+	}
 	if scratchSlice == nil {
-		scratchZeroed = true
 		scratchSlice = make([]float64, n*n2)
 	} else if len(scratchSlice) < n*n2 {
 		return ErrDim
 	}
-	scratch := NewDenseMatrix(n, n2, scratchSlice)
+	scratch := NewDenseMatrix(n, n2, scratchSlice[:n*n2])
+	s := scratch.data
+	// A may be out itself so its norm is needed before out is written.
+	anorm := Norm(A, 1)
+	stride := scratch.stride
 
-	// make scratch into the augmenting identity matrix
+	// make scratch into A augmented with the identity matrix
 	for i := 0; i < n; i++ {
-		ridx := i * scratch.stride
+		ridx := i * stride
 		for j := 0; j < n2; j++ {
-			if j < n {
-				scratch.data[ridx+j] = A.At(i, j)
+			switch {
+			case j < n:
+				s[ridx+j] = A.At(i, j)
+			case j == i+n:
+				s[ridx+j] = 1
+			default:
+				s[ridx+j] = 0
 			}
-			if j == i+n {
-				scratch.data[ridx+j] = 1
-			} else if !scratchZeroed {
-				// would not need this if we alloced (guaranteed zero)
-				// but to be safe, zero here
-				scratch.data[ridx+j] = 0
-			}
-		}
-	}
-	// exchange rows of the matrix, bottom-up
-	for i := n - 1; i > 0; i-- {
-		if scratch.At(i-1, 0) < scratch.At(i, 0) {
-			scratch.SwapRows(i, i-1)
 		}
 	}
 
-	// replace each row by sum of itself and a constant times another row
-	for i := 0; i < n; i++ {
-		// ic := i * scratch.c
-		for j := 0; j < n; j++ {
-			if i != j {
-				tmp := scratch.At(j, i) / scratch.At(i, i)
-				for k := 0; k < n2; k++ {
-					result := scratch.At(j, k) - scratch.At(i, k)*tmp
-					scratch.Set(j, k, result)
-				}
+	for k := 0; k < n; k++ {
+		// exchange rows so that the largest magnitude element of column k is the pivot
+		p := k
+		pmax := math.Abs(s[k*stride+k])
+		for i := k + 1; i < n; i++ {
+			if v := math.Abs(s[i*stride+k]); v > pmax {
+				p, pmax = i, v
 			}
 		}
-	}
-	const eps = 1e-16
-	// mul each row by a nonzero integer and divide each row by the diagonal
-	for i := 0; i < n; i++ {
-		tmp := scratch.At(i, i)
-		if math.Abs(tmp) < eps {
-			return ErrSingular
+		if pmax == 0 {
+			return ConditionError(0)
 		}
-		for j := 0; j < n2; j++ {
-			scratch.Set(i, j, scratch.At(i, j)/tmp)
+		if p != k {
+			scratch.SwapRows(p, k)
+		}
+		// divide the pivot row by the pivot
+		kidx := k * stride
+		pv := s[kidx+k]
+		for j := k; j < n2; j++ {
+			s[kidx+j] /= pv
+		}
+		// replace each row by sum of itself and a constant times the pivot row
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			ridx := i * stride
+			tmp := s[ridx+k]
+			if tmp == 0 {
+				continue
+			}
+			for j := k; j < n2; j++ {
+				s[ridx+j] -= s[kidx+j] * tmp
+			}
 		}
 	}
 
-	// scratch now contains the inverse of input in its lefthand half
-	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			out.Set(i, j, scratch.At(i, j))
-		}
+	// scratch now contains the inverse of input in its righthand half
+	out.Copy(scratch.Slice(0, n, n, n2))
+	rcond := 1 / (anorm * Norm(out, 1))
+	if rcond < epsilon || math.IsNaN(rcond) {
+		return ConditionError(rcond)
 	}
 	return nil
 }
@@ -10,4 +10,14 @@ func TestJacobiSVD(t *testing.T) {
 	if !vectorEqualTol(sigma, expect, 0.01) {
 		t.Error("sigma not equal to expect, ", sigma, expect)
 	}
+
+	// A wide matrix has one value per column, the extra ones zero.
+	wide := NewDenseMatrix(2, 3, []float64{
+		3, 0, 0,
+		0, 4, 0,
+	})
+	sigma = JacobiSVD(wide)
+	if !vectorEqualTol(sigma, NewDenseVector(3, []float64{0, 3, 4}), 1e-14) {
+		t.Error("wide sigma not equal to expect, ", sigma)
+	}
 }
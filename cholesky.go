@@ -0,0 +1,439 @@
+package lap
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNotPD is returned when a matrix is expected to be positive definite but is not.
+var ErrNotPD = errors.New("matrix is not positive definite")
+
+// Cholesky is a type for creating and using the Cholesky factorization of a
+// symmetric positive definite matrix
+//
+//	A = L * L^T
+//
+// where L is lower triangular with a positive diagonal.
+type Cholesky struct {
+	// l stores L on and below the diagonal. Elements above the diagonal are zero.
+	l  DenseM
+	ok bool
+}
+
+// Factorize computes the Cholesky factorization of the square matrix A and
+// stores the result in the receiver. Only the lower triangle of A is referenced,
+// A is assumed to be symmetric. Factorize returns false if A is not positive
+// definite, in which case subsequent solves return ErrNotPD.
+// Factorize panics with ErrDim if A is not square.
+func (ch *Cholesky) Factorize(A Matrix) (ok bool) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	if ch.l.r != n || ch.l.c != n {
+		ch.l = *NewDenseMatrix(n, n, nil)
+	}
+	ch.ok = cholFactorize[float64](ch.l.data, ch.l.stride, n, A)
+	return ch.ok
+}
+
+// LTo stores the lower triangular factor L in dst. If dst is not
+// initialized it is allocated automatically.
+func (ch *Cholesky) LTo(dst *DenseM) {
+	n := ch.l.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.Copy(&ch.l)
+}
+
+// Det returns the determinant of the factorized matrix.
+func (ch *Cholesky) Det() float64 {
+	return math.Exp(ch.LogDet())
+}
+
+// LogDet returns the log of the determinant of the factorized matrix.
+// The determinant of a positive definite matrix is always positive.
+func (ch *Cholesky) LogDet() float64 {
+	if !ch.ok {
+		return math.NaN()
+	}
+	var det float64
+	for i := 0; i < ch.l.r; i++ {
+		det += 2 * math.Log(ch.l.data[i*ch.l.stride+i])
+	}
+	return det
+}
+
+// Solve solves the system A*X = B using the factorization of A and stores
+// the result in dst. If dst is not initialized it is allocated automatically.
+// B and dst may be the same matrix.
+// Solve returns ErrNotPD if the factorization failed.
+func (ch *Cholesky) Solve(dst *DenseM, B Matrix) error {
+	n := ch.l.r
+	br, bc := B.Dims()
+	if br != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(br, bc, nil)
+	}
+	if r, c := dst.Dims(); r != br || c != bc {
+		panic(ErrDim)
+	}
+	if !ch.ok {
+		return ErrNotPD
+	}
+	if dst != B {
+		dst.Copy(B)
+	}
+	for j := 0; j < bc; j++ {
+		ch.solveInPlace(dst.ColView(j))
+	}
+	return nil
+}
+
+// SolveVec solves the system A*x = b using the factorization of A and stores
+// the result in dst. If dst is not initialized it is allocated automatically.
+// b and dst may be the same vector.
+// SolveVec returns ErrNotPD if the factorization failed.
+func (ch *Cholesky) SolveVec(dst *DenseV, b Vector) error {
+	n := ch.l.r
+	if b.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if !ch.ok {
+		return ErrNotPD
+	}
+	if dst != b {
+		dst.CopyVec(b)
+	}
+	ch.solveInPlace(dst)
+	return nil
+}
+
+// Inverse computes the inverse of the factorized matrix and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// Inverse returns ErrNotPD if the factorization failed.
+func (ch *Cholesky) Inverse(dst *DenseM) error {
+	n := ch.l.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	if !ch.ok {
+		return ErrNotPD
+	}
+	dst.Copy(Eye(n))
+	return ch.Solve(dst, dst)
+}
+
+// solveInPlace overwrites x with the solution of L*L^T*x = x.
+func (ch *Cholesky) solveInPlace(x *DenseV) {
+	cholSolve(ch.l.data, ch.l.stride, ch.l.r, x.data, x.incMinusOne+1)
+}
+
+// rcond estimates the reciprocal of the 1-norm condition number of the
+// factorized matrix, whose 1-norm is anorm, using Hager's method.
+func (ch *Cholesky) rcond(anorm float64) float64 {
+	if !ch.ok {
+		return 0
+	}
+	return cholRcond(ch.l.data, ch.l.stride, ch.l.r, anorm)
+}
+
+// cholFactorize stores the Cholesky factor of the nxn matrix A in l, stored
+// row major with the given stride, and reports whether A is positive definite.
+// Only the lower triangle of A is referenced. cholFactorize is shared by
+// Cholesky and CholeskyOf.
+func cholFactorize[T Float](l []T, stride, n int, A MatrixOf[T]) bool {
+	for j := 0; j < n; j++ {
+		jidx := j * stride
+		sum := A.At(j, j)
+		for k := 0; k < j; k++ {
+			sum -= l[jidx+k] * l[jidx+k]
+		}
+		if !(sum > 0) {
+			// Also catches NaN.
+			return false
+		}
+		ljj := sqrtOf(sum)
+		l[jidx+j] = ljj
+		for i := j + 1; i < n; i++ {
+			iidx := i * stride
+			sum := A.At(i, j)
+			for k := 0; k < j; k++ {
+				sum -= l[iidx+k] * l[jidx+k]
+			}
+			l[iidx+j] = sum / ljj
+			l[jidx+i] = 0
+		}
+	}
+	return true
+}
+
+// cholSolve overwrites the vector x of length n, whose elements are inc apart,
+// with the solution of L*L^T*x = x where l holds the factor computed by cholFactorize.
+func cholSolve[T Float](l []T, stride, n int, x []T, inc int) {
+	for i := 0; i < n; i++ {
+		sum := x[i*inc]
+		for k := 0; k < i; k++ {
+			sum -= l[i*stride+k] * x[k*inc]
+		}
+		x[i*inc] = sum / l[i*stride+i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i*inc]
+		for k := i + 1; k < n; k++ {
+			sum -= l[k*stride+i] * x[k*inc]
+		}
+		x[i*inc] = sum / l[i*stride+i]
+	}
+}
+
+// cholRcond estimates the reciprocal of the 1-norm condition number of the nxn
+// matrix with 1-norm anorm whose factor computed by cholFactorize is held in l.
+func cholRcond[T Float](l []T, stride, n int, anorm float64) float64 {
+	if n == 0 {
+		return 1
+	}
+	if anorm == 0 {
+		return 0
+	}
+	// A is symmetric so the transposed solve is the same.
+	ainvnorm := invNorm1(n, func(x []T, _ bool) { cholSolve(l, stride, n, x, 1) })
+	return 1 / (anorm * ainvnorm)
+}
+
+// Update updates the factorization in place so that it represents
+// the factorization of A + x*x^T. The receiver must hold a successful factorization.
+func (ch *Cholesky) Update(x Vector) {
+	n := ch.l.r
+	if x.Len() != n {
+		panic(ErrDim)
+	}
+	if !ch.ok {
+		panic(ErrNotPD)
+	}
+	work := make([]float64, n)
+	for i := range work {
+		work[i] = x.AtVec(i)
+	}
+	l := ch.l.data
+	stride := ch.l.stride
+	for k := 0; k < n; k++ {
+		kidx := k*stride + k
+		lkk := l[kidx]
+		r := math.Hypot(lkk, work[k])
+		c := r / lkk
+		s := work[k] / lkk
+		l[kidx] = r
+		for i := k + 1; i < n; i++ {
+			iidx := i*stride + k
+			l[iidx] = (l[iidx] + s*work[i]) / c
+			work[i] = c*work[i] - s*l[iidx]
+		}
+	}
+}
+
+// Downdate updates the factorization in place so that it represents
+// the factorization of A - x*x^T. Downdate returns false and leaves
+// the receiver unmodified if A - x*x^T is not positive definite.
+// The receiver must hold a successful factorization.
+func (ch *Cholesky) Downdate(x Vector) (ok bool) {
+	n := ch.l.r
+	if x.Len() != n {
+		panic(ErrDim)
+	}
+	if !ch.ok {
+		panic(ErrNotPD)
+	}
+	work := make([]float64, n)
+	for i := range work {
+		work[i] = x.AtVec(i)
+	}
+	var L DenseM
+	L.Copy(&ch.l)
+	l := L.data
+	stride := L.stride
+	for k := 0; k < n; k++ {
+		kidx := k*stride + k
+		lkk := l[kidx]
+		r2 := (lkk - work[k]) * (lkk + work[k])
+		if r2 <= 0 || math.IsNaN(r2) {
+			return false
+		}
+		r := math.Sqrt(r2)
+		c := r / lkk
+		s := work[k] / lkk
+		l[kidx] = r
+		for i := k + 1; i < n; i++ {
+			iidx := i*stride + k
+			l[iidx] = (l[iidx] - s*work[i]) / c
+			work[i] = c*work[i] - s*l[iidx]
+		}
+	}
+	ch.l = L
+	return true
+}
+
+// CholeskyOf is the generic counterpart of Cholesky for matrices with elements
+// of type T. It shares its implementation with Cholesky. Unlike Cholesky, the
+// solves of CholeskyOf return a ConditionError if the reciprocal condition
+// number is below the machine epsilon of T. CholeskyOf has no Update or
+// Downdate since the condition estimate needs the norm of the updated matrix,
+// which the factor alone does not give.
+type CholeskyOf[T Float] struct {
+	l  DenseMOf[T]
+	ok bool
+	// rcond is the estimated reciprocal 1-norm condition number of the
+	// factorized matrix.
+	rcond float64
+}
+
+// Factorize computes the Cholesky factorization of the square matrix A and
+// stores the result in the receiver. Only the lower triangle of A is referenced,
+// A is assumed to be symmetric. Factorize returns false if A is not positive
+// definite, in which case subsequent solves return ErrNotPD.
+// Factorize panics with ErrDim if A is not square.
+func (ch *CholeskyOf[T]) Factorize(A MatrixOf[T]) (ok bool) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	if ch.l.r != n || ch.l.c != n {
+		ch.l = *NewDenseMatrixOf[T](n, n, nil)
+	}
+	ch.ok = cholFactorize(ch.l.data, ch.l.stride, n, A)
+	ch.rcond = 0
+	if ch.ok {
+		ch.rcond = cholRcond(ch.l.data, ch.l.stride, n, float64(NormOf(A, 1)))
+	}
+	return ch.ok
+}
+
+// check returns ErrNotPD if the factorization failed and a ConditionError if
+// the factorized matrix is too ill-conditioned to solve with in the precision of T.
+func (ch *CholeskyOf[T]) check() error {
+	if !ch.ok {
+		return ErrNotPD
+	}
+	if ch.rcond < epsilonOf[T]() || math.IsNaN(ch.rcond) {
+		return ConditionError(ch.rcond)
+	}
+	return nil
+}
+
+// LTo stores the lower triangular factor L in dst. If dst is not
+// initialized it is allocated automatically.
+func (ch *CholeskyOf[T]) LTo(dst *DenseMOf[T]) {
+	n := ch.l.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.Copy(&ch.l)
+}
+
+// Det returns the determinant of the factorized matrix.
+func (ch *CholeskyOf[T]) Det() T {
+	if !ch.ok {
+		return T(math.NaN())
+	}
+	var det T = 1
+	for i := 0; i < ch.l.r; i++ {
+		lii := ch.l.data[i*ch.l.stride+i]
+		det *= lii * lii
+	}
+	return det
+}
+
+// LogDet returns the log of the determinant of the factorized matrix.
+// The determinant of a positive definite matrix is always positive.
+func (ch *CholeskyOf[T]) LogDet() float64 {
+	if !ch.ok {
+		return math.NaN()
+	}
+	var det float64
+	for i := 0; i < ch.l.r; i++ {
+		det += 2 * math.Log(float64(ch.l.data[i*ch.l.stride+i]))
+	}
+	return det
+}
+
+// Cond returns an estimate of the 1-norm condition number of the factorized
+// matrix. The returned value is +Inf if the factorization failed.
+func (ch *CholeskyOf[T]) Cond() float64 {
+	if ch.rcond == 0 {
+		return math.Inf(1)
+	}
+	return 1 / ch.rcond
+}
+
+// Solve solves the system A*X = B using the factorization of A and stores
+// the result in dst. If dst is not initialized it is allocated automatically.
+// B and dst may be the same matrix.
+// Solve returns ErrNotPD if the factorization failed and a ConditionError
+// if the factorized matrix is nearly singular.
+func (ch *CholeskyOf[T]) Solve(dst *DenseMOf[T], B MatrixOf[T]) error {
+	n := ch.l.r
+	br, bc := B.Dims()
+	if br != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](br, bc, nil)
+	}
+	if r, c := dst.Dims(); r != br || c != bc {
+		panic(ErrDim)
+	}
+	if err := ch.check(); err != nil {
+		return err
+	}
+	if dst != B {
+		dst.Copy(B)
+	}
+	for j := 0; j < bc; j++ {
+		cholSolve(ch.l.data, ch.l.stride, n, dst.data[j:], dst.stride)
+	}
+	return nil
+}
+
+// SolveVec solves the system A*x = b using the factorization of A and stores
+// the result in dst. If dst is not initialized it is allocated automatically.
+// b and dst may be the same vector.
+// SolveVec returns ErrNotPD if the factorization failed and a ConditionError
+// if the factorized matrix is nearly singular.
+func (ch *CholeskyOf[T]) SolveVec(dst *DenseVOf[T], b VectorOf[T]) error {
+	n := ch.l.r
+	if b.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVectorOf[T](n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if err := ch.check(); err != nil {
+		return err
+	}
+	if dst != b {
+		dst.CopyVec(b)
+	}
+	cholSolve(ch.l.data, ch.l.stride, n, dst.data, dst.incMinusOne+1)
+	return nil
+}
@@ -0,0 +1,120 @@
+package lap
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomSPD returns a random symmetric positive definite nxn matrix.
+func randomSPD(rng *rand.Rand, n int) *DenseM {
+	B := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	var A DenseM
+	A.Mul(B, T(B))
+	for i := 0; i < n; i++ {
+		A.Set(i, i, A.At(i, i)+float64(n))
+	}
+	return &A
+}
+
+func TestCholesky(t *testing.T) {
+	const n = 5
+	rng := rand.New(rand.NewSource(1))
+	A := randomSPD(rng, n)
+	var ch Cholesky
+	if !ch.Factorize(A) {
+		t.Fatal("expected positive definite matrix")
+	}
+	var L, LLT DenseM
+	ch.LTo(&L)
+	LLT.Mul(&L, T(&L))
+	if !matrixEqualTol(&LLT, A, 1e-12) {
+		t.Error("L*L^T != A")
+	}
+
+	want := NewDenseVector(n, randomSlice(rng, n))
+	var b, x DenseV
+	b.MulVec(A, want)
+	err := ch.SolveVec(&x, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !vectorEqualTol(&x, want, 1e-12) {
+		t.Error("bad solution")
+	}
+
+	var inv, ident DenseM
+	err = ch.Inverse(&inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident.Mul(A, &inv)
+	if !matrixEqualTol(&ident, Eye(n), 1e-12) {
+		t.Error("A*inv(A) is not identity")
+	}
+
+	var lu LU
+	lu.Factorize(A)
+	if !almostEqual(ch.Det(), lu.Det(), 1e-9*math.Abs(lu.Det())) {
+		t.Errorf("determinant mismatch: cholesky=%g lu=%g", ch.Det(), lu.Det())
+	}
+}
+
+func TestCholeskyUpdateDowndate(t *testing.T) {
+	const n = 4
+	rng := rand.New(rand.NewSource(2))
+	A := randomSPD(rng, n)
+	x := NewDenseVector(n, randomSlice(rng, n))
+	var xxT, Aup DenseM
+	xxT.Mul(x, T(x))
+	Aup.Add(A, &xxT)
+
+	var ch, want Cholesky
+	ch.Factorize(A)
+	want.Factorize(&Aup)
+	ch.Update(x)
+	var got, expect DenseM
+	ch.LTo(&got)
+	want.LTo(&expect)
+	if !matrixEqualTol(&got, &expect, 1e-12) {
+		t.Errorf("bad update:\n%v\n%v", Formatted(&got), Formatted(&expect))
+	}
+
+	if !ch.Downdate(x) {
+		t.Fatal("downdate failed")
+	}
+	want.Factorize(A)
+	ch.LTo(&got)
+	want.LTo(&expect)
+	if !matrixEqualTol(&got, &expect, 1e-12) {
+		t.Errorf("bad downdate:\n%v\n%v", Formatted(&got), Formatted(&expect))
+	}
+
+	// Downdating by a large vector makes the matrix indefinite.
+	big := NewDenseVector(n, nil)
+	big.SetVec(0, 1e3)
+	if ch.Downdate(big) {
+		t.Error("expected downdate to fail")
+	}
+	ch.LTo(&got)
+	if !matrixEqualTol(&got, &expect, 1e-12) {
+		t.Error("failed downdate modified factorization")
+	}
+}
+
+func TestCholeskyNotPD(t *testing.T) {
+	A := NewDenseMatrix(2, 2, []float64{
+		1, 2,
+		2, 1,
+	})
+	var ch Cholesky
+	if ch.Factorize(A) {
+		t.Fatal("expected indefinite matrix to fail factorization")
+	}
+	var x DenseV
+	err := ch.SolveVec(&x, NewDenseVector(2, nil))
+	if !errors.Is(err, ErrNotPD) {
+		t.Errorf("expected ErrNotPD, got %v", err)
+	}
+}
@@ -0,0 +1,368 @@
+package lap
+
+import "sort"
+
+var (
+	_ Matrix = &CSR{}
+	_ Matrix = &CSC{}
+)
+
+// CSR is a compressed sparse row matrix. Non-zero entries of row i are stored
+// contiguously and sorted by column index, which makes row iteration and
+// matrix-vector products efficient. The sparsity pattern of a CSR matrix is
+// fixed once constructed.
+type CSR struct {
+	compressed
+}
+
+// CSC is a compressed sparse column matrix. Non-zero entries of column j are
+// stored contiguously and sorted by row index, which makes column iteration and
+// transposed matrix-vector products efficient. The sparsity pattern of a CSC
+// matrix is fixed once constructed.
+type CSC struct {
+	compressed
+}
+
+// NewCSR creates a compressed sparse row matrix with the non-zero entries of s.
+func NewCSR(s *Sparse) *CSR {
+	I, J, V := s.triplets()
+	return &CSR{compressed: newCompressed(s.r, s.c, I, J, V)}
+}
+
+// NewCSRFromAccum creates an rxc compressed sparse row matrix from the entries
+// of the accumulator. Duplicate entries are summed.
+func NewCSRFromAccum(r, c int, acc SparseAccum) *CSR {
+	checkAccum(r, c, acc)
+	return &CSR{compressed: newCompressed(r, c, acc.I, acc.J, acc.V)}
+}
+
+// NewCSC creates a compressed sparse column matrix with the non-zero entries of s.
+func NewCSC(s *Sparse) *CSC {
+	I, J, V := s.triplets()
+	return &CSC{compressed: newCompressed(s.c, s.r, J, I, V)}
+}
+
+// NewCSCFromAccum creates an rxc compressed sparse column matrix from the entries
+// of the accumulator. Duplicate entries are summed.
+func NewCSCFromAccum(r, c int, acc SparseAccum) *CSC {
+	checkAccum(r, c, acc)
+	return &CSC{compressed: newCompressed(c, r, acc.J, acc.I, acc.V)}
+}
+
+// Dims returns the dimensions of the matrix.
+func (m *CSR) Dims() (int, int) { return m.nmajor, m.nminor }
+
+// At returns the element at ith row, jth column.
+func (m *CSR) At(i, j int) float64 {
+	if i < 0 || i >= m.nmajor {
+		panic(ErrRowAccess)
+	} else if j < 0 || j >= m.nminor {
+		panic(ErrColAccess)
+	}
+	return m.at(i, j)
+}
+
+// CountNonZero returns the number of stored elements.
+func (m *CSR) CountNonZero() int { return len(m.data) }
+
+// DoNonZero iterates over all stored elements in row-major order.
+// The function fn takes a row/column index and the element value at (i, j).
+func (m *CSR) DoNonZero(fn func(i, j int, v float64)) {
+	for i := 0; i < m.nmajor; i++ {
+		m.doMajor(i, fn)
+	}
+}
+
+// DoRowNonZero iterates over the stored elements of row i in increasing column order.
+// The function fn takes a row/column index and the element value at (i, j).
+func (m *CSR) DoRowNonZero(i int, fn func(i, j int, v float64)) {
+	if i < 0 || i >= m.nmajor {
+		panic(ErrRowAccess)
+	}
+	m.doMajor(i, fn)
+}
+
+// MulVecTo computes A*x and stores the result in dst. If dst is not
+// initialized it is allocated automatically.
+func (m *CSR) MulVecTo(dst *DenseV, x Vector) {
+	m.mulMajor(dst, x)
+}
+
+// MulVecTransTo computes A^T*x and stores the result in dst. If dst is not
+// initialized it is allocated automatically.
+func (m *CSR) MulVecTransTo(dst *DenseV, x Vector) {
+	m.mulMinor(dst, x)
+}
+
+// DenseTo stores the matrix in dst. If dst is not initialized it is allocated automatically.
+func (m *CSR) DenseTo(dst *DenseM) {
+	m.denseTo(dst, false)
+}
+
+// ToCSC returns a compressed sparse column copy of the matrix.
+func (m *CSR) ToCSC() *CSC {
+	return &CSC{compressed: m.transpose()}
+}
+
+// Dims returns the dimensions of the matrix.
+func (m *CSC) Dims() (int, int) { return m.nminor, m.nmajor }
+
+// At returns the element at ith row, jth column.
+func (m *CSC) At(i, j int) float64 {
+	if i < 0 || i >= m.nminor {
+		panic(ErrRowAccess)
+	} else if j < 0 || j >= m.nmajor {
+		panic(ErrColAccess)
+	}
+	return m.at(j, i)
+}
+
+// CountNonZero returns the number of stored elements.
+func (m *CSC) CountNonZero() int { return len(m.data) }
+
+// DoNonZero iterates over all stored elements in column-major order.
+// The function fn takes a row/column index and the element value at (i, j).
+func (m *CSC) DoNonZero(fn func(i, j int, v float64)) {
+	for j := 0; j < m.nmajor; j++ {
+		m.DoColNonZero(j, fn)
+	}
+}
+
+// DoColNonZero iterates over the stored elements of column j in increasing row order.
+// The function fn takes a row/column index and the element value at (i, j).
+func (m *CSC) DoColNonZero(j int, fn func(i, j int, v float64)) {
+	if j < 0 || j >= m.nmajor {
+		panic(ErrColAccess)
+	}
+	m.doMajor(j, func(j, i int, v float64) { fn(i, j, v) })
+}
+
+// MulVecTo computes A*x and stores the result in dst. If dst is not
+// initialized it is allocated automatically.
+func (m *CSC) MulVecTo(dst *DenseV, x Vector) {
+	m.mulMinor(dst, x)
+}
+
+// MulVecTransTo computes A^T*x and stores the result in dst. If dst is not
+// initialized it is allocated automatically.
+func (m *CSC) MulVecTransTo(dst *DenseV, x Vector) {
+	m.mulMajor(dst, x)
+}
+
+// DenseTo stores the matrix in dst. If dst is not initialized it is allocated automatically.
+func (m *CSC) DenseTo(dst *DenseM) {
+	m.denseTo(dst, true)
+}
+
+// ToCSR returns a compressed sparse row copy of the matrix.
+func (m *CSC) ToCSR() *CSR {
+	return &CSR{compressed: m.transpose()}
+}
+
+// compressed is the storage shared by CSR and CSC matrices. The entries of
+// major index k (the row of a CSR or the column of a CSC matrix) are stored in
+// ind[indptr[k]:indptr[k+1]] and data[indptr[k]:indptr[k+1]] with ind holding
+// the minor indices in increasing order.
+type compressed struct {
+	nmajor, nminor int
+	indptr         []int
+	ind            []int
+	data           []float64
+}
+
+// newCompressed builds compressed storage from triplets. Duplicate entries
+// are summed and entries that sum to zero are dropped.
+func newCompressed(nmajor, nminor int, major, minor []int, v []float64) compressed {
+	// Counting sort by major index.
+	indptr := make([]int, nmajor+1)
+	for _, k := range major {
+		indptr[k+1]++
+	}
+	for k := 0; k < nmajor; k++ {
+		indptr[k+1] += indptr[k]
+	}
+	ind := make([]int, len(v))
+	data := make([]float64, len(v))
+	next := make([]int, nmajor)
+	copy(next, indptr)
+	for n, k := range major {
+		ind[next[k]] = minor[n]
+		data[next[k]] = v[n]
+		next[k]++
+	}
+	// Sort each major segment by minor index and sum duplicates in place.
+	var w int
+	start := 0
+	for k := 0; k < nmajor; k++ {
+		end := indptr[k+1]
+		sort.Sort(byIndex{ind: ind[start:end], data: data[start:end]})
+		segStart := w
+		for n := start; n < end; n++ {
+			if w > segStart && ind[w-1] == ind[n] {
+				data[w-1] += data[n]
+				continue
+			}
+			ind[w] = ind[n]
+			data[w] = data[n]
+			w++
+		}
+		// Drop explicit zeros.
+		wz := segStart
+		for n := segStart; n < w; n++ {
+			if data[n] != 0 {
+				ind[wz] = ind[n]
+				data[wz] = data[n]
+				wz++
+			}
+		}
+		w = wz
+		start = end
+		indptr[k+1] = w
+	}
+	return compressed{
+		nmajor: nmajor,
+		nminor: nminor,
+		indptr: indptr,
+		ind:    ind[:w:w],
+		data:   data[:w:w],
+	}
+}
+
+type byIndex struct {
+	ind  []int
+	data []float64
+}
+
+func (b byIndex) Len() int           { return len(b.ind) }
+func (b byIndex) Less(i, j int) bool { return b.ind[i] < b.ind[j] }
+func (b byIndex) Swap(i, j int) {
+	b.ind[i], b.ind[j] = b.ind[j], b.ind[i]
+	b.data[i], b.data[j] = b.data[j], b.data[i]
+}
+
+func (c *compressed) at(major, minor int) float64 {
+	start, end := c.indptr[major], c.indptr[major+1]
+	seg := c.ind[start:end]
+	n := sort.SearchInts(seg, minor)
+	if n < len(seg) && seg[n] == minor {
+		return c.data[start+n]
+	}
+	return 0
+}
+
+func (c *compressed) doMajor(k int, fn func(major, minor int, v float64)) {
+	for n := c.indptr[k]; n < c.indptr[k+1]; n++ {
+		fn(k, c.ind[n], c.data[n])
+	}
+}
+
+// mulMajor computes dst[k] = sum over the entries of major index k of v*x[minor].
+func (c *compressed) mulMajor(dst *DenseV, x Vector) {
+	if x.Len() != c.nminor {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(c.nmajor, nil)
+	} else if aliasedData(dst, x) {
+		panic(ErrAliasedData)
+	}
+	if dst.Len() != c.nmajor {
+		panic(ErrDim)
+	}
+	for k := 0; k < c.nmajor; k++ {
+		var sum float64
+		for n := c.indptr[k]; n < c.indptr[k+1]; n++ {
+			sum += c.data[n] * x.AtVec(c.ind[n])
+		}
+		dst.SetVec(k, sum)
+	}
+}
+
+// mulMinor computes dst[minor] = sum over the entries of minor index of v*x[major].
+func (c *compressed) mulMinor(dst *DenseV, x Vector) {
+	if x.Len() != c.nmajor {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(c.nminor, nil)
+	} else if aliasedData(dst, x) {
+		panic(ErrAliasedData)
+	}
+	if dst.Len() != c.nminor {
+		panic(ErrDim)
+	}
+	dst.DoSetVec(func(int, float64) float64 { return 0 })
+	for k := 0; k < c.nmajor; k++ {
+		xk := x.AtVec(k)
+		if xk == 0 {
+			continue
+		}
+		for n := c.indptr[k]; n < c.indptr[k+1]; n++ {
+			i := c.ind[n]
+			dst.SetVec(i, dst.AtVec(i)+c.data[n]*xk)
+		}
+	}
+}
+
+// denseTo scatters the entries into dst. If trans is true the major index is
+// the column index.
+func (c *compressed) denseTo(dst *DenseM, trans bool) {
+	r, cols := c.nmajor, c.nminor
+	if trans {
+		r, cols = cols, r
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(r, cols, nil)
+	}
+	if dr, dc := dst.Dims(); dr != r || dc != cols {
+		panic(ErrDim)
+	}
+	dst.DoSet(func(int, int, float64) float64 { return 0 })
+	for k := 0; k < c.nmajor; k++ {
+		for n := c.indptr[k]; n < c.indptr[k+1]; n++ {
+			if trans {
+				dst.data[c.ind[n]*dst.stride+k] = c.data[n]
+			} else {
+				dst.data[k*dst.stride+c.ind[n]] = c.data[n]
+			}
+		}
+	}
+}
+
+// transpose returns the storage with major and minor indices exchanged.
+func (c *compressed) transpose() compressed {
+	major := make([]int, len(c.data))
+	for k := 0; k < c.nmajor; k++ {
+		for n := c.indptr[k]; n < c.indptr[k+1]; n++ {
+			major[n] = k
+		}
+	}
+	return newCompressed(c.nminor, c.nmajor, c.ind, major, c.data)
+}
+
+// triplets returns the non-zero entries of s in coordinate form.
+func (s *Sparse) triplets() (I, J []int, V []float64) {
+	n := len(s.m)
+	I = make([]int, 0, n)
+	J = make([]int, 0, n)
+	V = make([]float64, 0, n)
+	s.DoNonZero(func(i, j int, v float64) {
+		I = append(I, i)
+		J = append(J, j)
+		V = append(V, v)
+	})
+	return I, J, V
+}
+
+func checkAccum(r, c int, acc SparseAccum) {
+	if len(acc.I) != len(acc.J) || len(acc.V) != len(acc.I) {
+		panic("length of arguments must be equal")
+	}
+	for n := range acc.I {
+		if acc.I[n] < 0 || acc.I[n] >= r {
+			panic(ErrRowAccess)
+		} else if acc.J[n] < 0 || acc.J[n] >= c {
+			panic(ErrColAccess)
+		}
+	}
+}
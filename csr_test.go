@@ -0,0 +1,75 @@
+package lap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCSR(t *testing.T) {
+	const r, c = 6, 4
+	rng := rand.New(rand.NewSource(1))
+	acc := NewSparseAccum(20)
+	for n := range acc.V {
+		acc.Set(n, rng.Intn(r), rng.Intn(c), rng.Float64())
+	}
+	s := NewSparse(r, c)
+	s.Accumulate(acc)
+	var want DenseM
+	want.Copy(s)
+
+	csr := NewCSRFromAccum(r, c, acc)
+	csc := NewCSCFromAccum(r, c, acc)
+	for _, A := range []Matrix{csr, csc, NewCSR(s), NewCSC(s), csr.ToCSC(), csc.ToCSR()} {
+		if !matrixEqualTol(A, &want, 1e-15) {
+			t.Errorf("%T does not match accumulated sparse matrix", A)
+		}
+	}
+	if csr.CountNonZero() != s.CountNonZero() || csc.CountNonZero() != s.CountNonZero() {
+		t.Errorf("duplicates were not summed: %d, %d != %d", csr.CountNonZero(), csc.CountNonZero(), s.CountNonZero())
+	}
+
+	var dense DenseM
+	csr.DenseTo(&dense)
+	if !matrixEqualTol(&dense, &want, 1e-15) {
+		t.Error("CSR DenseTo mismatch")
+	}
+	csc.DenseTo(&dense)
+	if !matrixEqualTol(&dense, &want, 1e-15) {
+		t.Error("CSC DenseTo mismatch")
+	}
+
+	x := NewDenseVector(c, randomSlice(rng, c))
+	y := NewDenseVector(r, randomSlice(rng, r))
+	var wantAx, wantATy DenseV
+	wantAx.MulVec(&want, x)
+	wantATy.MulVec(T(&want), y)
+	for _, A := range []interface {
+		MulVecTo(dst *DenseV, x Vector)
+		MulVecTransTo(dst *DenseV, x Vector)
+	}{csr, csc} {
+		var Ax, ATy DenseV
+		A.MulVecTo(&Ax, x)
+		A.MulVecTransTo(&ATy, y)
+		if !vectorEqualTol(&Ax, &wantAx, 1e-14) {
+			t.Errorf("%T: bad A*x", A)
+		}
+		if !vectorEqualTol(&ATy, &wantATy, 1e-14) {
+			t.Errorf("%T: bad A^T*y", A)
+		}
+	}
+
+	lastI, lastJ := -1, -1
+	csr.DoNonZero(func(i, j int, v float64) {
+		if i < lastI || (i == lastI && j <= lastJ) {
+			t.Errorf("CSR iteration out of order at (%d,%d)", i, j)
+		}
+		lastI, lastJ = i, j
+	})
+	lastI, lastJ = -1, -1
+	csc.DoNonZero(func(i, j int, v float64) {
+		if j < lastJ || (j == lastJ && i <= lastI) {
+			t.Errorf("CSC iteration out of order at (%d,%d)", i, j)
+		}
+		lastI, lastJ = i, j
+	})
+}
@@ -0,0 +1,197 @@
+package lap
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVOption is a functional option for reading and writing CSV data.
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	delim   rune
+	skip    int
+	columns []int
+	missing float64
+	fill    bool
+	drop    bool
+	header  []string
+}
+
+// CSVDelimiter sets the field delimiter. The default is a comma.
+func CSVDelimiter(r rune) CSVOption {
+	return func(c *csvConfig) { c.delim = r }
+}
+
+// CSVSkipRows sets the number of leading records, such as header rows,
+// that ReadCSV discards.
+func CSVSkipRows(n int) CSVOption {
+	return func(c *csvConfig) { c.skip = n }
+}
+
+// CSVColumns selects the zero-based columns that are read or written and
+// their order. By default all columns are used.
+func CSVColumns(cols ...int) CSVOption {
+	return func(c *csvConfig) { c.columns = cols }
+}
+
+// CSVMissing sets the value ReadCSV stores for missing fields. A field is
+// missing if it is empty or only contains white space. By default a
+// missing field is an error.
+func CSVMissing(v float64) CSVOption {
+	return func(c *csvConfig) { c.missing = v; c.fill = true; c.drop = false }
+}
+
+// CSVDropMissing makes ReadCSV discard records with a missing field in
+// any of the selected columns.
+func CSVDropMissing() CSVOption {
+	return func(c *csvConfig) { c.drop = true; c.fill = false }
+}
+
+// CSVHeader sets the column names WriteCSV writes as the first record.
+// The number of names must match the number of written columns.
+func CSVHeader(names ...string) CSVOption {
+	return func(c *csvConfig) { c.header = names }
+}
+
+func newCSVConfig(opts []CSVOption) csvConfig {
+	c := csvConfig{delim: ','}
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// ReadCSV reads numeric CSV data from r into a new matrix with one row per
+// record. All records must have the same number of fields unless columns are
+// selected with CSVColumns, in which case records only need to contain the
+// selected columns. Fields are parsed with strconv.ParseFloat after trimming
+// surrounding white space.
+//
+// Parse errors are returned as a *ParseError whose Line is the one-based
+// number of the record, counting skipped records, on which the error occurred.
+func ReadCSV(r io.Reader, opts ...CSVOption) (*DenseM, error) {
+	cfg := newCSVConfig(opts)
+	cr := csv.NewReader(r)
+	cr.Comma = cfg.delim
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	var (
+		data   []float64
+		rows   int
+		cols   = -1
+		record int
+		row    []float64
+	)
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		record++
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				err = perr.Err
+			}
+			return nil, &ParseError{Format: "csv", Line: record, Err: err}
+		}
+		if record <= cfg.skip {
+			continue
+		}
+		n := len(fields)
+		if cfg.columns != nil {
+			n = len(cfg.columns)
+		}
+		if cols < 0 {
+			cols = n
+			row = make([]float64, cols)
+		} else if cfg.columns == nil && n != cols {
+			return nil, &ParseError{Format: "csv", Line: record, Err: fmt.Errorf("got %d fields, want %d", n, cols)}
+		}
+		missing := false
+		for k := range row {
+			idx := k
+			if cfg.columns != nil {
+				idx = cfg.columns[k]
+				if idx < 0 || idx >= len(fields) {
+					return nil, &ParseError{Format: "csv", Line: record, Err: fmt.Errorf("column %d out of range of %d fields", idx, len(fields))}
+				}
+			}
+			s := strings.TrimSpace(fields[idx])
+			if s == "" {
+				switch {
+				case cfg.fill:
+					row[k] = cfg.missing
+					continue
+				case cfg.drop:
+					missing = true
+					continue
+				}
+				return nil, &ParseError{Format: "csv", Line: record, Err: fmt.Errorf("missing value in column %d", idx)}
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, &ParseError{Format: "csv", Line: record, Err: fmt.Errorf("invalid number %q in column %d", s, idx)}
+			}
+			row[k] = v
+		}
+		if missing {
+			continue
+		}
+		data = append(data, row...)
+		rows++
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	if data == nil {
+		data = []float64{}
+	}
+	return NewDenseMatrix(rows, cols, data), nil
+}
+
+// WriteCSV writes m to w as CSV with one record per row. Values are written
+// with the precision needed to read them back exactly. The CSVDelimiter,
+// CSVColumns and CSVHeader options are used, the others are ignored.
+// Non-finite values are written as NaN, +Inf and -Inf.
+func WriteCSV(w io.Writer, m Matrix, opts ...CSVOption) error {
+	cfg := newCSVConfig(opts)
+	rows, cols := m.Dims()
+	columns := cfg.columns
+	if columns == nil {
+		columns = make([]int, cols)
+		irange(columns, 0, 1)
+	}
+	for _, j := range columns {
+		if j < 0 || j >= cols {
+			return ErrColAccess
+		}
+	}
+	if cfg.header != nil && len(cfg.header) != len(columns) {
+		return fmt.Errorf("%d header names for %d columns: %w", len(cfg.header), len(columns), ErrDim)
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = cfg.delim
+	if cfg.header != nil {
+		if err := cw.Write(cfg.header); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(columns))
+	for i := 0; i < rows; i++ {
+		for k, j := range columns {
+			record[k] = strconv.FormatFloat(m.At(i, j), 'g', -1, 64)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,137 @@
+package lap
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	const input = `time,x,y,label
+0, 1.5, -2, a
+1, , 3e2, b
+2, 4, 5, "c,d"
+`
+	for _, test := range []struct {
+		name string
+		opts []CSVOption
+		want *DenseM
+		line int
+	}{
+		{
+			name: "missing is error",
+			opts: []CSVOption{CSVSkipRows(1), CSVColumns(0, 1, 2)},
+			line: 3,
+		},
+		{
+			name: "fill missing",
+			opts: []CSVOption{CSVSkipRows(1), CSVColumns(0, 1, 2), CSVMissing(math.NaN())},
+			want: NewDenseMatrix(3, 3, []float64{0, 1.5, -2, 1, math.NaN(), 300, 2, 4, 5}),
+		},
+		{
+			name: "drop missing",
+			opts: []CSVOption{CSVSkipRows(1), CSVColumns(2, 1), CSVDropMissing()},
+			want: NewDenseMatrix(2, 2, []float64{-2, 1.5, 5, 4}),
+		},
+		{
+			name: "header is not numeric",
+			opts: []CSVOption{CSVColumns(0)},
+			line: 1,
+		},
+		{
+			name: "label column is not numeric",
+			opts: []CSVOption{CSVSkipRows(1), CSVMissing(0)},
+			line: 2,
+		},
+		{
+			name: "column out of range",
+			opts: []CSVOption{CSVSkipRows(1), CSVColumns(4)},
+			line: 2,
+		},
+	} {
+		got, err := ReadCSV(strings.NewReader(input), test.opts...)
+		if test.want == nil {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Errorf("%s: expected ParseError, got %v", test.name, err)
+			} else if perr.Line != test.line {
+				t.Errorf("%s: got error on line %d, want %d: %v", test.name, perr.Line, test.line, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !matrixEqualNaN(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got.data, test.want.data)
+		}
+	}
+
+	got, err := ReadCSV(strings.NewReader("1;2\n3;4\n"), CSVDelimiter(';'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matrixEqual(got, NewDenseMatrix(2, 2, []float64{1, 2, 3, 4})) {
+		t.Errorf("got %v", got.data)
+	}
+	_, err = ReadCSV(strings.NewReader("1,2\n3\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("expected ragged record error on line 2, got %v", err)
+	}
+	got, err = ReadCSV(strings.NewReader(""))
+	if r, c := got.Dims(); err != nil || r != 0 || c != 0 {
+		t.Errorf("expected empty matrix, got %dx%d, %v", r, c, err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	m := NewDenseMatrix(2, 3, []float64{1.0 / 3, math.Inf(1), -2, math.NaN(), 0, 1e300})
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, m, CSVHeader("a", "b", "c")); err != nil {
+		t.Fatal(err)
+	}
+	want := "a,b,c\n0.3333333333333333,+Inf,-2\nNaN,0,1e+300\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	got, err := ReadCSV(&buf, CSVSkipRows(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matrixEqualNaN(got, m) {
+		t.Errorf("round trip mismatch: %v", got.data)
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, m, CSVDelimiter('\t'), CSVColumns(2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "-2\t0.3333333333333333\n1e+300\tNaN\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if err := WriteCSV(&buf, m, CSVHeader("a")); !errors.Is(err, ErrDim) {
+		t.Errorf("expected ErrDim, got %v", err)
+	}
+}
+
+// matrixEqualNaN is like matrixEqual but NaN values compare equal.
+func matrixEqualNaN(a, b Matrix) bool {
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	if ar != br || ac != bc {
+		return false
+	}
+	for i := 0; i < ar; i++ {
+		for j := 0; j < ac; j++ {
+			x, y := a.At(i, j), b.At(i, j)
+			if x != y && !(math.IsNaN(x) && math.IsNaN(y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,773 @@
+package lap
+
+// EigenKind specifies which eigenvectors are computed by Eigen.
+type EigenKind int
+
+const (
+	// EigenNone specifies that no eigenvectors be computed.
+	EigenNone EigenKind = 0
+	// EigenLeft specifies that the left eigenvectors be computed.
+	EigenLeft EigenKind = 1 << 0
+	// EigenRight specifies that the right eigenvectors be computed.
+	EigenRight EigenKind = 1 << 1
+	// EigenBoth specifies that both left and right eigenvectors be computed.
+	EigenBoth = EigenLeft | EigenRight
+)
+
+// Eigen is a type for computing the eigenvalues and eigenvectors of a general
+// real square matrix. The matrix is reduced to upper Hessenberg form with
+// Householder similarity transformations and then to real Schur form with the
+// implicit double-shift QR algorithm.
+//
+// Complex eigenvalues of a real matrix come in conjugate pairs. The pair is
+// stored contiguously with the eigenvalue of positive imaginary part first.
+// Eigenvectors are stored in real form: if λ_j, λ_j+1 are a conjugate pair then
+// columns j and j+1 of the eigenvector matrix hold the real and imaginary parts
+// of the eigenvector of λ_j. The eigenvector of λ_j+1 is its complex conjugate.
+// Right eigenvectors satisfy A*v = λ*v and left eigenvectors satisfy w^H*A = λ*w^H.
+type Eigen struct {
+	kind   EigenKind
+	values []complex128
+	right  DenseM
+	left   DenseM
+}
+
+// Factorize computes the eigenvalues of the square matrix A and the eigenvectors
+// specified by kind, storing the result in the receiver.
+// Factorize returns false if the QR iteration failed to converge or if the left
+// eigenvectors were requested and A is defective. After a failure the receiver
+// holds no eigenvalues and VectorsTo and LeftVectorsTo panic.
+// Factorize panics with ErrDim if A is not square.
+func (e *Eigen) Factorize(A Matrix, kind EigenKind) (ok bool) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	// The kind is only recorded once the factorization succeeds so that the
+	// vector accessors panic after a failure.
+	e.kind = EigenNone
+	e.right, e.left = DenseM{}, DenseM{}
+	e.values = e.values[:0]
+	values, right, left, ok := eigenFactorize[float64](A, kind)
+	if !ok {
+		return false
+	}
+	if kind&EigenRight != 0 {
+		e.right = *NewDenseMatrix(n, n, right)
+	}
+	if kind&EigenLeft != 0 {
+		e.left = *NewDenseMatrix(n, n, left)
+	}
+	e.kind = kind
+	e.values = values
+	return true
+}
+
+// eigenFactorize computes the eigenvalues of the nxn matrix A and the eigenvectors
+// specified by kind. The right and left eigenvectors are returned in real form
+// in the columns of nxn matrices stored contiguously in row major order.
+// eigenFactorize returns false if the QR iteration failed to converge or if the
+// left eigenvectors were requested and A is defective.
+// eigenFactorize is shared by Eigen and EigenOf.
+func eigenFactorize[T Float](A MatrixOf[T], kind EigenKind) (values []complex128, right, left []T, ok bool) {
+	n, _ := A.Dims()
+	h := make([]T, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			h[i*n+j] = A.At(i, j)
+		}
+	}
+	H := rowsOf(h, n, n, n)
+	wantV := kind != EigenNone
+	var v []T
+	var V [][]T
+	if wantV {
+		v = make([]T, n*n)
+		V = rowsOf(v, n, n, n)
+	}
+	orthes(H, V)
+	d := make([]T, n)
+	ev := make([]T, n)
+	if !hqr2(H, V, d, ev) {
+		return nil, nil, nil, false
+	}
+	values = make([]complex128, n)
+	for i := range values {
+		values[i] = complex(float64(d[i]), float64(ev[i]))
+	}
+	if !wantV {
+		return values, nil, nil, true
+	}
+	normalizeEigenvectors(V, ev)
+	if kind&EigenLeft != 0 {
+		// The rows of the inverse of the eigenvector matrix are the
+		// conjugated left eigenvectors. In real form this amounts to
+		// transposing the inverse of V, whose column j solves V^T*x = e_j.
+		lu := make([]T, n*n)
+		copy(lu, v)
+		pivot := make([]int, n)
+		luFactorize(lu, n, n, pivot)
+		if hasZeroDiag(lu, n, n) {
+			return nil, nil, nil, false
+		}
+		left = make([]T, n*n)
+		for j := 0; j < n; j++ {
+			left[j*n+j] = 1
+			luSolve(lu, n, n, pivot, left[j:], n, true)
+		}
+		normalizeEigenvectors(rowsOf(left, n, n, n), ev)
+	}
+	if kind&EigenRight != 0 {
+		right = v
+	}
+	return values, right, left, true
+}
+
+// Values returns the eigenvalues of the factorized matrix. If dst is nil a new
+// slice is allocated, otherwise dst must have length n.
+func (e *Eigen) Values(dst []complex128) []complex128 {
+	if dst == nil {
+		dst = make([]complex128, len(e.values))
+	}
+	if len(dst) != len(e.values) {
+		panic(ErrDim)
+	}
+	copy(dst, e.values)
+	return dst
+}
+
+// VectorsTo stores the right eigenvectors of the factorized matrix in real form
+// in dst. If dst is not initialized it is allocated automatically.
+// VectorsTo panics if the right eigenvectors were not computed.
+func (e *Eigen) VectorsTo(dst *DenseM) {
+	if e.kind&EigenRight == 0 {
+		panic("right eigenvectors not computed")
+	}
+	eigenVectorsTo(dst, &e.right)
+}
+
+// LeftVectorsTo stores the left eigenvectors of the factorized matrix in real form
+// in dst. If dst is not initialized it is allocated automatically.
+// LeftVectorsTo panics if the left eigenvectors were not computed.
+func (e *Eigen) LeftVectorsTo(dst *DenseM) {
+	if e.kind&EigenLeft == 0 {
+		panic("left eigenvectors not computed")
+	}
+	eigenVectorsTo(dst, &e.left)
+}
+
+func eigenVectorsTo(dst, src *DenseM) {
+	n := src.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.Copy(src)
+}
+
+// normalizeEigenvectors scales the real form eigenvectors in the columns of the
+// matrix with rows V to unit 2-norm. ev holds the imaginary parts of the eigenvalues.
+func normalizeEigenvectors[T Float](V [][]T, ev []T) {
+	n := len(ev)
+	for j := 0; j < n; j++ {
+		cols := 1
+		if ev[j] > 0 && j+1 < n {
+			// Real and imaginary part of the complex vector.
+			cols = 2
+		}
+		var norm T
+		for k := j; k < j+cols; k++ {
+			var sum T
+			for _, row := range V {
+				sum += row[k] * row[k]
+			}
+			norm = hypotOf(norm, sqrtOf(sum))
+		}
+		if norm != 0 {
+			for k := j; k < j+cols; k++ {
+				for _, row := range V {
+					row[k] /= norm
+				}
+			}
+		}
+		j += cols - 1
+	}
+}
+
+// rows returns a slice of row views of A.
+func (A *DenseM) rows() [][]float64 {
+	return rowsOf(A.data, A.stride, A.r, A.c)
+}
+
+// rowsOf returns a slice of row views of the rxc matrix a stored row major
+// with the given stride.
+func rowsOf[T Float](a []T, stride, r, c int) [][]T {
+	rows := make([][]T, r)
+	for i := range rows {
+		rows[i] = a[i*stride : i*stride+c]
+	}
+	return rows
+}
+
+// orthes reduces the square matrix with rows H to upper Hessenberg form with
+// Householder similarity transformations and accumulates the transformations
+// in the matrix with rows V if V is not nil.
+//
+// orthes is derived from the EISPACK routines orthes and ortran
+// by way of the public domain JAMA library.
+func orthes[T Float](H, V [][]T) {
+	n := len(H)
+	ort := make([]T, n)
+	low, high := 0, n-1
+	for m := low + 1; m <= high-1; m++ {
+		// Scale column.
+		var scale T
+		for i := m; i <= high; i++ {
+			scale += absOf(H[i][m-1])
+		}
+		if scale == 0 {
+			continue
+		}
+		// Compute Householder transformation.
+		var h T
+		for i := high; i >= m; i-- {
+			ort[i] = H[i][m-1] / scale
+			h += ort[i] * ort[i]
+		}
+		g := sqrtOf(h)
+		if ort[m] > 0 {
+			g = -g
+		}
+		h -= ort[m] * g
+		ort[m] -= g
+		// Apply Householder similarity transformation
+		// H = (I-u*u'/h)*H*(I-u*u')/h)
+		for j := m; j < n; j++ {
+			var f T
+			for i := high; i >= m; i-- {
+				f += ort[i] * H[i][j]
+			}
+			f /= h
+			for i := m; i <= high; i++ {
+				H[i][j] -= f * ort[i]
+			}
+		}
+		for i := 0; i <= high; i++ {
+			var f T
+			for j := high; j >= m; j-- {
+				f += ort[j] * H[i][j]
+			}
+			f /= h
+			for j := m; j <= high; j++ {
+				H[i][j] -= f * ort[j]
+			}
+		}
+		ort[m] *= scale
+		H[m][m-1] = scale * g
+	}
+	if V == nil {
+		return
+	}
+
+	// Accumulate transformations.
+	for i, row := range V {
+		for j := range row {
+			row[j] = 0
+		}
+		row[i] = 1
+	}
+	for m := high - 1; m >= low+1; m-- {
+		if H[m][m-1] == 0 {
+			continue
+		}
+		for i := m + 1; i <= high; i++ {
+			ort[i] = H[i][m-1]
+		}
+		for j := m; j <= high; j++ {
+			var g T
+			for i := m; i <= high; i++ {
+				g += ort[i] * V[i][j]
+			}
+			// Double division avoids possible underflow.
+			g = (g / ort[m]) / H[m][m-1]
+			for i := m; i <= high; i++ {
+				V[i][j] += g * ort[i]
+			}
+		}
+	}
+}
+
+// cdiv performs complex scalar division (xr+i*xi)/(yr+i*yi).
+func cdiv[T Float](xr, xi, yr, yi T) (T, T) {
+	var r, d T
+	if absOf(yr) > absOf(yi) {
+		r = yi / yr
+		d = yr + r*yi
+		return (xr + r*xi) / d, (xi - r*xr) / d
+	}
+	r = yr / yi
+	d = yi + r*yr
+	return (r*xr + xi) / d, (r*xi - xr) / d
+}
+
+// hqr2 reduces the upper Hessenberg matrix with rows H to real Schur form with
+// the implicit double-shift QR algorithm, storing the real and imaginary parts of
+// the eigenvalues in d and e. If V is not nil it must contain the transformations
+// of the Hessenberg reduction and on return holds the right eigenvectors in real form.
+// hqr2 returns false if the iteration fails to converge.
+//
+// hqr2 is derived from the EISPACK routine hqr2 by way of the public domain JAMA library.
+func hqr2[T Float](H, V [][]T, d, e []T) bool {
+	const maxIter = 30
+	nn := len(H)
+	n := nn - 1
+	low, high := 0, nn-1
+	eps := T(epsilonOf[T]())
+	var exshift, p, q, r, s, z, t, w, x, y T
+
+	// Compute matrix norm.
+	var norm T
+	for i := 0; i < nn; i++ {
+		for j := max(i-1, 0); j < nn; j++ {
+			norm += absOf(H[i][j])
+		}
+	}
+
+	// Outer loop over eigenvalue index.
+	iter := 0
+	for n >= low {
+		// Look for single small sub-diagonal element.
+		l := n
+		for l > low {
+			s = absOf(H[l-1][l-1]) + absOf(H[l][l])
+			if s == 0 {
+				s = norm
+			}
+			if absOf(H[l][l-1]) < eps*s {
+				break
+			}
+			l--
+		}
+
+		// Check for convergence.
+		switch {
+		case l == n:
+			// One root found.
+			H[n][n] += exshift
+			d[n] = H[n][n]
+			e[n] = 0
+			n--
+			iter = 0
+
+		case l == n-1:
+			// Two roots found.
+			w = H[n][n-1] * H[n-1][n]
+			p = (H[n-1][n-1] - H[n][n]) / 2
+			q = p*p + w
+			z = sqrtOf(absOf(q))
+			H[n][n] += exshift
+			H[n-1][n-1] += exshift
+			x = H[n][n]
+			if q >= 0 {
+				// Real pair.
+				if p >= 0 {
+					z = p + z
+				} else {
+					z = p - z
+				}
+				d[n-1] = x + z
+				d[n] = d[n-1]
+				if z != 0 {
+					d[n] = x - w/z
+				}
+				e[n-1] = 0
+				e[n] = 0
+				x = H[n][n-1]
+				s = absOf(x) + absOf(z)
+				p = x / s
+				q = z / s
+				r = sqrtOf(p*p + q*q)
+				p /= r
+				q /= r
+				// Row modification.
+				for j := n - 1; j < nn; j++ {
+					z = H[n-1][j]
+					H[n-1][j] = q*z + p*H[n][j]
+					H[n][j] = q*H[n][j] - p*z
+				}
+				// Column modification.
+				for i := 0; i <= n; i++ {
+					z = H[i][n-1]
+					H[i][n-1] = q*z + p*H[i][n]
+					H[i][n] = q*H[i][n] - p*z
+				}
+				// Accumulate transformations.
+				for i := low; V != nil && i <= high; i++ {
+					z = V[i][n-1]
+					V[i][n-1] = q*z + p*V[i][n]
+					V[i][n] = q*V[i][n] - p*z
+				}
+			} else {
+				// Complex pair.
+				d[n-1] = x + p
+				d[n] = x + p
+				e[n-1] = z
+				e[n] = -z
+			}
+			n -= 2
+			iter = 0
+
+		default:
+			// No convergence yet.
+			if iter > maxIter*nn {
+				return false
+			}
+			// Form shift.
+			x = H[n][n]
+			y = 0
+			w = 0
+			if l < n {
+				y = H[n-1][n-1]
+				w = H[n][n-1] * H[n-1][n]
+			}
+			// Wilkinson's original ad hoc shift.
+			if iter == 10 {
+				exshift += x
+				for i := low; i <= n; i++ {
+					H[i][i] -= x
+				}
+				s = absOf(H[n][n-1]) + absOf(H[n-1][n-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+			// MATLAB's new ad hoc shift.
+			if iter == 30 {
+				s = (y - x) / 2
+				s = s*s + w
+				if s > 0 {
+					s = sqrtOf(s)
+					if y < x {
+						s = -s
+					}
+					s = x - w/((y-x)/2+s)
+					for i := low; i <= n; i++ {
+						H[i][i] -= s
+					}
+					exshift += s
+					x = 0.964
+					y = x
+					w = x
+				}
+			}
+			iter++
+
+			// Look for two consecutive small sub-diagonal elements.
+			m := n - 2
+			for m >= l {
+				z = H[m][m]
+				r = x - z
+				s = y - z
+				p = (r*s-w)/H[m+1][m] + H[m][m+1]
+				q = H[m+1][m+1] - z - r - s
+				r = H[m+2][m+1]
+				s = absOf(p) + absOf(q) + absOf(r)
+				p /= s
+				q /= s
+				r /= s
+				if m == l {
+					break
+				}
+				if absOf(H[m][m-1])*(absOf(q)+absOf(r)) <
+					eps*(absOf(p)*(absOf(H[m-1][m-1])+absOf(z)+absOf(H[m+1][m+1]))) {
+					break
+				}
+				m--
+			}
+			for i := m + 2; i <= n; i++ {
+				H[i][i-2] = 0
+				if i > m+2 {
+					H[i][i-3] = 0
+				}
+			}
+
+			// Double QR step involving rows l:n and columns m:n.
+			for k := m; k <= n-1; k++ {
+				notlast := k != n-1
+				if k != m {
+					p = H[k][k-1]
+					q = H[k+1][k-1]
+					r = 0
+					if notlast {
+						r = H[k+2][k-1]
+					}
+					x = absOf(p) + absOf(q) + absOf(r)
+					if x == 0 {
+						continue
+					}
+					p /= x
+					q /= x
+					r /= x
+				}
+				s = sqrtOf(p*p + q*q + r*r)
+				if p < 0 {
+					s = -s
+				}
+				if s == 0 {
+					continue
+				}
+				if k != m {
+					H[k][k-1] = -s * x
+				} else if l != m {
+					H[k][k-1] = -H[k][k-1]
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+				// Row modification.
+				for j := k; j < nn; j++ {
+					p = H[k][j] + q*H[k+1][j]
+					if notlast {
+						p += r * H[k+2][j]
+						H[k+2][j] -= p * z
+					}
+					H[k][j] -= p * x
+					H[k+1][j] -= p * y
+				}
+				// Column modification.
+				for i := 0; i <= min(n, k+3); i++ {
+					p = x*H[i][k] + y*H[i][k+1]
+					if notlast {
+						p += z * H[i][k+2]
+						H[i][k+2] -= p * r
+					}
+					H[i][k] -= p
+					H[i][k+1] -= p * q
+				}
+				// Accumulate transformations.
+				for i := low; V != nil && i <= high; i++ {
+					p = x*V[i][k] + y*V[i][k+1]
+					if notlast {
+						p += z * V[i][k+2]
+						V[i][k+2] -= p * r
+					}
+					V[i][k] -= p
+					V[i][k+1] -= p * q
+				}
+			}
+		}
+	}
+
+	if V == nil || norm == 0 {
+		return true
+	}
+
+	// Backsubstitute to find vectors of upper triangular form.
+	for n = nn - 1; n >= 0; n-- {
+		p = d[n]
+		q = e[n]
+		switch {
+		case q == 0:
+			// Real vector.
+			l := n
+			H[n][n] = 1
+			for i := n - 1; i >= 0; i-- {
+				w = H[i][i] - p
+				r = 0
+				for j := l; j <= n; j++ {
+					r += H[i][j] * H[j][n]
+				}
+				if e[i] < 0 {
+					z = w
+					s = r
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					if w != 0 {
+						H[i][n] = -r / w
+					} else {
+						H[i][n] = -r / (eps * norm)
+					}
+				} else {
+					// Solve real equations.
+					x = H[i][i+1]
+					y = H[i+1][i]
+					q = (d[i]-p)*(d[i]-p) + e[i]*e[i]
+					t = (x*s - z*r) / q
+					H[i][n] = t
+					if absOf(x) > absOf(z) {
+						H[i+1][n] = (-r - w*t) / x
+					} else {
+						H[i+1][n] = (-s - y*t) / z
+					}
+				}
+				// Overflow control.
+				t = absOf(H[i][n])
+				if (eps*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						H[j][n] /= t
+					}
+				}
+			}
+
+		case q < 0:
+			// Complex vector.
+			l := n - 1
+			// Last vector component imaginary so matrix is triangular.
+			if absOf(H[n][n-1]) > absOf(H[n-1][n]) {
+				H[n-1][n-1] = q / H[n][n-1]
+				H[n-1][n] = -(H[n][n] - p) / H[n][n-1]
+			} else {
+				H[n-1][n-1], H[n-1][n] = cdiv(0, -H[n-1][n], H[n-1][n-1]-p, q)
+			}
+			H[n][n-1] = 0
+			H[n][n] = 1
+			for i := n - 2; i >= 0; i-- {
+				var ra, sa T
+				for j := l; j <= n; j++ {
+					ra += H[i][j] * H[j][n-1]
+					sa += H[i][j] * H[j][n]
+				}
+				w = H[i][i] - p
+				if e[i] < 0 {
+					z = w
+					r = ra
+					s = sa
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					H[i][n-1], H[i][n] = cdiv(-ra, -sa, w, q)
+				} else {
+					// Solve complex equations.
+					x = H[i][i+1]
+					y = H[i+1][i]
+					vr := (d[i]-p)*(d[i]-p) + e[i]*e[i] - q*q
+					vi := (d[i] - p) * 2 * q
+					if vr == 0 && vi == 0 {
+						vr = eps * norm * (absOf(w) + absOf(q) + absOf(x) + absOf(y) + absOf(z))
+					}
+					H[i][n-1], H[i][n] = cdiv(x*r-z*ra+q*sa, x*s-z*sa-q*ra, vr, vi)
+					if absOf(x) > (absOf(z) + absOf(q)) {
+						H[i+1][n-1] = (-ra - w*H[i][n-1] + q*H[i][n]) / x
+						H[i+1][n] = (-sa - w*H[i][n] - q*H[i][n-1]) / x
+					} else {
+						H[i+1][n-1], H[i+1][n] = cdiv(-r-y*H[i][n-1], -s-y*H[i][n], z, q)
+					}
+				}
+				// Overflow control.
+				t = absOf(H[i][n-1])
+				if a := absOf(H[i][n]); a > t {
+					t = a
+				}
+				if (eps*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						H[j][n-1] /= t
+						H[j][n] /= t
+					}
+				}
+			}
+		}
+	}
+
+	// Back transformation to get eigenvectors of original matrix.
+	for j := nn - 1; j >= low; j-- {
+		for i := low; i <= high; i++ {
+			z = 0
+			for k := low; k <= min(j, high); k++ {
+				z += V[i][k] * H[k][j]
+			}
+			V[i][j] = z
+		}
+	}
+	return true
+}
+
+// EigenOf is the generic counterpart of Eigen for matrices with elements of
+// type T. It shares its implementation with Eigen. The eigenvalues are
+// returned as complex128 for every T.
+type EigenOf[T Float] struct {
+	kind   EigenKind
+	values []complex128
+	right  DenseMOf[T]
+	left   DenseMOf[T]
+}
+
+// Factorize computes the eigenvalues of the square matrix A and the eigenvectors
+// specified by kind, storing the result in the receiver.
+// Factorize returns false if the QR iteration failed to converge or if the left
+// eigenvectors were requested and A is defective. After a failure the receiver
+// holds no eigenvalues and VectorsTo and LeftVectorsTo panic.
+// Factorize panics with ErrDim if A is not square.
+func (e *EigenOf[T]) Factorize(A MatrixOf[T], kind EigenKind) (ok bool) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	e.kind = EigenNone
+	e.right, e.left = DenseMOf[T]{}, DenseMOf[T]{}
+	e.values = e.values[:0]
+	values, right, left, ok := eigenFactorize(A, kind)
+	if !ok {
+		return false
+	}
+	if kind&EigenRight != 0 {
+		e.right = *NewDenseMatrixOf(n, n, right)
+	}
+	if kind&EigenLeft != 0 {
+		e.left = *NewDenseMatrixOf(n, n, left)
+	}
+	e.kind = kind
+	e.values = values
+	return true
+}
+
+// Values returns the eigenvalues of the factorized matrix. If dst is nil a new
+// slice is allocated, otherwise dst must have length n.
+func (e *EigenOf[T]) Values(dst []complex128) []complex128 {
+	if dst == nil {
+		dst = make([]complex128, len(e.values))
+	}
+	if len(dst) != len(e.values) {
+		panic(ErrDim)
+	}
+	copy(dst, e.values)
+	return dst
+}
+
+// VectorsTo stores the right eigenvectors of the factorized matrix in real form
+// in dst. If dst is not initialized it is allocated automatically.
+// VectorsTo panics if the right eigenvectors were not computed.
+func (e *EigenOf[T]) VectorsTo(dst *DenseMOf[T]) {
+	if e.kind&EigenRight == 0 {
+		panic("right eigenvectors not computed")
+	}
+	eigenVectorsToOf(dst, &e.right)
+}
+
+// LeftVectorsTo stores the left eigenvectors of the factorized matrix in real form
+// in dst. If dst is not initialized it is allocated automatically.
+// LeftVectorsTo panics if the left eigenvectors were not computed.
+func (e *EigenOf[T]) LeftVectorsTo(dst *DenseMOf[T]) {
+	if e.kind&EigenLeft == 0 {
+		panic("left eigenvectors not computed")
+	}
+	eigenVectorsToOf(dst, &e.left)
+}
+
+func eigenVectorsToOf[T Float](dst, src *DenseMOf[T]) {
+	n := src.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.Copy(src)
+}
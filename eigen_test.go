@@ -0,0 +1,129 @@
+package lap
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// complexVectors unpacks the real form eigenvectors in the columns of V.
+func complexVectors(V *DenseM, values []complex128) [][]complex128 {
+	n := len(values)
+	vecs := make([][]complex128, n)
+	for j := 0; j < n; j++ {
+		vecs[j] = make([]complex128, n)
+		for i := 0; i < n; i++ {
+			switch {
+			case imag(values[j]) == 0:
+				vecs[j][i] = complex(V.At(i, j), 0)
+			case imag(values[j]) > 0:
+				vecs[j][i] = complex(V.At(i, j), V.At(i, j+1))
+			default:
+				vecs[j][i] = complex(V.At(i, j-1), -V.At(i, j))
+			}
+		}
+	}
+	return vecs
+}
+
+func TestEigen(t *testing.T) {
+	rot := NewDenseMatrix(2, 2, []float64{
+		0, -1,
+		1, 0,
+	})
+	var eig Eigen
+	if !eig.Factorize(rot, EigenNone) {
+		t.Fatal("did not converge")
+	}
+	values := eig.Values(nil)
+	if cmplx.Abs(values[0]-1i) > 1e-14 || cmplx.Abs(values[1]+1i) > 1e-14 {
+		t.Errorf("expected eigenvalues ±i, got %v", values)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 3, 6, 9} {
+		A := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+		if !eig.Factorize(A, EigenBoth) {
+			t.Fatalf("n=%d: did not converge", n)
+		}
+		values := eig.Values(nil)
+		var VR, VL DenseM
+		eig.VectorsTo(&VR)
+		eig.LeftVectorsTo(&VL)
+		right := complexVectors(&VR, values)
+		left := complexVectors(&VL, values)
+		for j, lambda := range values {
+			for i := 0; i < n; i++ {
+				var Av, wA complex128
+				for k := 0; k < n; k++ {
+					Av += complex(A.At(i, k), 0) * right[j][k]
+					wA += cmplx.Conj(left[j][k]) * complex(A.At(k, i), 0)
+				}
+				if cmplx.Abs(Av-lambda*right[j][i]) > 1e-10 {
+					t.Errorf("n=%d: A*v != λ*v for λ=%v", n, lambda)
+				}
+				if cmplx.Abs(wA-lambda*cmplx.Conj(left[j][i])) > 1e-10 {
+					t.Errorf("n=%d: w^H*A != λ*w^H for λ=%v", n, lambda)
+				}
+			}
+		}
+	}
+
+	// Eigenvalues of a symmetric matrix agree with EigenSym.
+	S := NewDenseMatrix(3, 3, []float64{
+		2, -1, 0,
+		-1, 2, -1,
+		0, -1, 2,
+	})
+	var sym EigenSym
+	sym.Factorize(S, false)
+	eig.Factorize(S, EigenNone)
+	got := eig.Values(nil)
+	re := make([]float64, len(got))
+	for i, v := range got {
+		re[i] = real(v)
+		if imag(v) != 0 {
+			t.Errorf("symmetric matrix has complex eigenvalue %v", v)
+		}
+	}
+	sort.Float64s(re)
+	if !vectorEqualTol(NewDenseVector(3, re), NewDenseVector(3, sym.Values(nil)), 1e-12) {
+		t.Errorf("eigenvalue mismatch: %v vs %v", re, sym.Values(nil))
+	}
+}
+
+func TestEigenFailure(t *testing.T) {
+	var eig Eigen
+	if !eig.Factorize(magic3, EigenBoth) {
+		t.Fatal("did not converge")
+	}
+	// The QR iteration does not converge for a matrix with NaN elements.
+	A := NewDenseMatrix(4, 4, nil)
+	A.DoSet(func(i, j int, _ float64) float64 {
+		if i == j {
+			return math.NaN()
+		}
+		return 1
+	})
+	if eig.Factorize(A, EigenBoth) {
+		t.Fatal("expected factorization of NaN matrix to fail")
+	}
+	if values := eig.Values(nil); len(values) != 0 {
+		t.Errorf("expected no eigenvalues after failure, got %v", values)
+	}
+	for name, fn := range map[string]func(*DenseM){
+		"VectorsTo":     eig.VectorsTo,
+		"LeftVectorsTo": eig.LeftVectorsTo,
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic after failed factorization", name)
+				}
+			}()
+			fn(&DenseM{})
+		}()
+	}
+}
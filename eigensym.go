@@ -0,0 +1,221 @@
+package lap
+
+import "sort"
+
+// EigenSym is a type for computing the eigenvalue decomposition of a
+// symmetric matrix
+//
+//	A = V * Λ * V^T
+//
+// where Λ is the diagonal matrix of eigenvalues and the columns of V are
+// orthonormal eigenvectors. The decomposition is computed with the cyclic
+// Jacobi eigenvalue algorithm.
+type EigenSym struct {
+	vectorsComputed bool
+	values          []float64
+	vectors         DenseM
+}
+
+// Factorize computes the eigenvalue decomposition of the symmetric matrix A and
+// stores the result in the receiver. Only the lower triangle of A is referenced.
+// If vectors is true the eigenvectors are also computed.
+// Factorize returns false if the Jacobi iteration failed to converge.
+// Factorize panics with ErrDim if A is not square.
+func (e *EigenSym) Factorize(A Matrix, vectors bool) (ok bool) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	values, vecs, ok := eigenSymFactorize[float64](A, vectors)
+	e.values = values
+	e.vectorsComputed = vectors
+	e.vectors = DenseM{}
+	if vectors {
+		e.vectors = *NewDenseMatrix(n, n, vecs)
+	}
+	return ok
+}
+
+// eigenSymFactorize computes the eigenvalues of the symmetric nxn matrix A in
+// ascending order with the cyclic Jacobi eigenvalue algorithm. Only the lower
+// triangle of A is referenced. If vectors is true the corresponding eigenvectors
+// are returned in the columns of an nxn matrix stored contiguously in row major
+// order. eigenSymFactorize is shared by EigenSym and EigenSymOf.
+func eigenSymFactorize[T Float](A MatrixOf[T], vectors bool) (values, vecs []T, ok bool) {
+	const maxSweeps = 60
+	n, _ := A.Dims()
+	w := make([]T, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i >= j {
+				w[i*n+j] = A.At(i, j)
+			} else {
+				w[i*n+j] = A.At(j, i)
+			}
+		}
+	}
+	var v []T
+	if vectors {
+		v = make([]T, n*n)
+		for i := 0; i < n; i++ {
+			v[i*n+i] = 1
+		}
+	}
+	var norm T
+	for _, x := range w {
+		norm += x * x
+	}
+	norm = sqrtOf(norm)
+	eps := T(epsilonOf[T]())
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		var off T
+		for i := 0; i < n; i++ {
+			for j := 0; j < i; j++ {
+				x := w[i*n+j]
+				off += 2 * x * x
+			}
+		}
+		if sqrtOf(off) <= eps*norm {
+			ok = true
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := w[p*n+q]
+				if apq == 0 {
+					continue
+				}
+				c, s, _ := jacobiRotation(w[p*n+p], apq, w[q*n+q])
+				rotateCols(w, n, n, p, q, c, s)
+				rotateRows(w, n, n, p, q, c, s)
+				w[p*n+q] = 0
+				w[q*n+p] = 0
+				if vectors {
+					rotateCols(v, n, n, p, q, c, s)
+				}
+			}
+		}
+	}
+
+	diag := make([]T, n)
+	for i := range diag {
+		diag[i] = w[i*n+i]
+	}
+	order := make([]int, n)
+	irange(order, 0, 1)
+	sort.SliceStable(order, func(i, j int) bool { return diag[order[i]] < diag[order[j]] })
+	values = make([]T, n)
+	for i, idx := range order {
+		values[i] = diag[idx]
+	}
+	if vectors {
+		vecs = make([]T, n*n)
+		for i, idx := range order {
+			for r := 0; r < n; r++ {
+				vecs[r*n+i] = v[r*n+idx]
+			}
+		}
+	}
+	return values, vecs, ok
+}
+
+// rotateRows applies the transposed plane rotation [c s; -s c]^T to rows p and q
+// of the matrix a with cols columns stored row major with the given stride.
+func rotateRows[T Float](a []T, stride, cols, p, q int, c, s T) {
+	pidx, qidx := p*stride, q*stride
+	for j := 0; j < cols; j++ {
+		ap, aq := a[pidx+j], a[qidx+j]
+		a[pidx+j] = c*ap - s*aq
+		a[qidx+j] = s*ap + c*aq
+	}
+}
+
+// Values returns the eigenvalues of the factorized matrix in ascending order.
+// If dst is nil a new slice is allocated, otherwise dst must have length n.
+func (e *EigenSym) Values(dst []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, len(e.values))
+	}
+	if len(dst) != len(e.values) {
+		panic(ErrDim)
+	}
+	copy(dst, e.values)
+	return dst
+}
+
+// VectorsTo stores the orthonormal eigenvectors of the factorized matrix in the
+// columns of dst. The ith column corresponds to the ith eigenvalue returned by Values.
+// If dst is not initialized it is allocated automatically.
+// VectorsTo panics if the eigenvectors were not computed.
+func (e *EigenSym) VectorsTo(dst *DenseM) {
+	if !e.vectorsComputed {
+		panic("eigenvectors not computed")
+	}
+	n := len(e.values)
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.Copy(&e.vectors)
+}
+
+// EigenSymOf is the generic counterpart of EigenSym for matrices with elements
+// of type T. It shares its implementation with EigenSym.
+type EigenSymOf[T Float] struct {
+	vectorsComputed bool
+	values          []T
+	vectors         DenseMOf[T]
+}
+
+// Factorize computes the eigenvalue decomposition of the symmetric matrix A and
+// stores the result in the receiver. Only the lower triangle of A is referenced.
+// If vectors is true the eigenvectors are also computed.
+// Factorize returns false if the Jacobi iteration failed to converge.
+// Factorize panics with ErrDim if A is not square.
+func (e *EigenSymOf[T]) Factorize(A MatrixOf[T], vectors bool) (ok bool) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	values, vecs, ok := eigenSymFactorize(A, vectors)
+	e.values = values
+	e.vectorsComputed = vectors
+	e.vectors = DenseMOf[T]{}
+	if vectors {
+		e.vectors = *NewDenseMatrixOf(n, n, vecs)
+	}
+	return ok
+}
+
+// Values returns the eigenvalues of the factorized matrix in ascending order.
+// If dst is nil a new slice is allocated, otherwise dst must have length n.
+func (e *EigenSymOf[T]) Values(dst []T) []T {
+	if dst == nil {
+		dst = make([]T, len(e.values))
+	}
+	if len(dst) != len(e.values) {
+		panic(ErrDim)
+	}
+	copy(dst, e.values)
+	return dst
+}
+
+// VectorsTo stores the orthonormal eigenvectors of the factorized matrix in the
+// columns of dst. The ith column corresponds to the ith eigenvalue returned by Values.
+// If dst is not initialized it is allocated automatically.
+// VectorsTo panics if the eigenvectors were not computed.
+func (e *EigenSymOf[T]) VectorsTo(dst *DenseMOf[T]) {
+	if !e.vectorsComputed {
+		panic("eigenvectors not computed")
+	}
+	n := len(e.values)
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.Copy(&e.vectors)
+}
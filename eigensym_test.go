@@ -0,0 +1,55 @@
+package lap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEigenSym(t *testing.T) {
+	A := NewDenseMatrix(3, 3, []float64{
+		2, -1, 0,
+		-1, 2, -1,
+		0, -1, 2,
+	})
+	var eig EigenSym
+	if !eig.Factorize(A, false) {
+		t.Fatal("did not converge")
+	}
+	// Eigenvalues of the 1D Laplacian are 2-sqrt(2), 2, 2+sqrt(2).
+	want := NewDenseVector(3, []float64{0.5857864376269049, 2, 3.414213562373095})
+	got := NewDenseVector(3, eig.Values(nil))
+	if !vectorEqualTol(got, want, 1e-12) {
+		t.Errorf("got eigenvalues %v, want %v", got.data, want.data)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	const n = 6
+	B := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	var S DenseM
+	S.Add(B, T(B))
+	if !eig.Factorize(&S, true) {
+		t.Fatal("did not converge")
+	}
+	var V, VTV DenseM
+	eig.VectorsTo(&V)
+	VTV.Mul(T(&V), &V)
+	if !matrixEqualTol(&VTV, Eye(n), 1e-12) {
+		t.Error("eigenvectors not orthonormal")
+	}
+	values := eig.Values(nil)
+	for i := 1; i < n; i++ {
+		if values[i] < values[i-1] {
+			t.Fatalf("eigenvalues not ascending: %v", values)
+		}
+	}
+	for j := 0; j < n; j++ {
+		var Av, lv DenseV
+		v := V.ColView(j)
+		Av.MulVec(&S, v)
+		lv.CopyVec(v)
+		lv.DoSetVec(func(_ int, x float64) float64 { return values[j] * x })
+		if !vectorEqualTol(&Av, &lv, 1e-12) {
+			t.Errorf("A*v != λ*v for eigenvalue %g", values[j])
+		}
+	}
+}
@@ -8,12 +8,12 @@ package lap
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
 
 // Formatted returns a fmt.Formatter for the matrix m using the given options.
-// It does not support FormatOptions yet.
 func Formatted(m Matrix, options ...FormatOption) fmt.Formatter {
 	f := formatter{
 		matrix: m,
@@ -41,8 +41,7 @@ type FormatOption func(*formatter)
 // Format satisfies the fmt.Formatter interface.
 func (f formatter) Format(fs fmt.State, c rune) {
 	if c == 'v' && fs.Flag('#') && f.format == nil {
-		fmt.Fprintf(fs, "%#v", f.matrix)
-		return
+		f.format = formatGo
 	}
 	if f.format == nil {
 		f.format = format
@@ -50,13 +49,92 @@ func (f formatter) Format(fs fmt.State, c rune) {
 	f.format(f.matrix, f.prefix, f.margin, f.dot, f.squeeze, fs, c)
 }
 
+// Excerpt sets the maximum number of rows and columns to print at the margins of the matrix
+// to m. If m is zero or less all elements are printed.
+func Excerpt(m int) FormatOption {
+	return func(f *formatter) { f.margin = m }
+}
+
+// Prefix sets the formatted prefix to the string p. Prefix is a string that is prepended to
+// each line of output after the first line.
+func Prefix(p string) FormatOption {
+	return func(f *formatter) { f.prefix = p }
+}
+
+// Squeeze sets the printing behavior to minimise column width for each individual column.
+func Squeeze() FormatOption {
+	return func(f *formatter) { f.squeeze = true }
+}
+
+// DotByte sets the dot character to b. The dot character is used to replace zero elements
+// if the result is printed with the fmt ' ' verb flag. Without a DotByte option, the default
+// dot character is '.'.
+func DotByte(b byte) FormatOption {
+	return func(f *formatter) { f.dot = b }
+}
+
 // FormatMATLAB sets the printing behavior to output MATLAB syntax. If MATLAB syntax is
 // specified, the ' ' verb flag and Excerpt option are ignored. If the alternative syntax
 // verb flag, '#' is used the matrix is formatted in rows and columns.
+// The output can be read back with ParseMATLAB.
 func FormatMATLAB() FormatOption {
 	return func(f *formatter) { f.format = formatMATLAB }
 }
 
+// FormatPython sets the printing behavior to output NumPy syntax. The matrix is
+// written as np.array([[...], [...]]) and vectors as np.array([...]). Non-finite
+// values are written as np.nan, np.inf and -np.inf. If Python syntax is specified,
+// the ' ' verb flag and Excerpt option are ignored. If the alternative syntax verb
+// flag, '#' is used the matrix is formatted in rows and columns.
+func FormatPython() FormatOption {
+	return func(f *formatter) { f.format = formatPython }
+}
+
+// FormatJulia sets the printing behavior to output Julia syntax. The matrix is
+// written as [a b; c d]. Non-finite values are written as NaN, Inf and -Inf.
+// If Julia syntax is specified, the ' ' verb flag and Excerpt option are ignored.
+// If the alternative syntax verb flag, '#' is used the matrix is formatted in
+// rows and columns.
+func FormatJulia() FormatOption {
+	return func(f *formatter) { f.format = formatJulia }
+}
+
+// FormatLaTeX sets the printing behavior to output a LaTeX matrix in the environment
+// env, such as "bmatrix", "pmatrix" or "vmatrix". If env is empty "bmatrix" is used.
+// Non-finite values are written as \infty, -\infty and \mathrm{NaN}. Zero elements
+// are written as the dot character if the ' ' verb flag is used or m is a sparse matrix.
+// The Excerpt option is ignored. If the alternative syntax verb flag, '#' is used the
+// matrix is formatted in rows and columns.
+func FormatLaTeX(env string) FormatOption {
+	if env == "" {
+		env = "bmatrix"
+	}
+	return func(f *formatter) {
+		f.format = func(m Matrix, prefix string, margin int, dot byte, squeeze bool, fs fmt.State, c rune) {
+			formatLaTeX(env, m, prefix, margin, dot, squeeze, fs, c)
+		}
+	}
+}
+
+// FormatMarkdown sets the printing behavior to output a GitHub flavored Markdown table
+// with the column indices as header. Zero elements are written as the dot character if
+// the ' ' verb flag is used or m is a sparse matrix. The Excerpt option is ignored.
+// If the alternative syntax verb flag, '#' is used the columns are padded to equal width.
+func FormatMarkdown() FormatOption {
+	return func(f *formatter) { f.format = formatMarkdown }
+}
+
+// FormatGo sets the printing behavior to output Go syntax that constructs the matrix with
+// lap.NewDenseMatrix, or lap.NewDenseVector for vectors. Values are written with the
+// precision needed to reconstruct them exactly. Only the %v verb is supported, precision
+// and width flags and the Excerpt option are ignored. If the alternative syntax verb flag,
+// '#' is used the matrix is formatted in rows with one row per line.
+//
+// Printing a Formatted matrix with %#v without a format option uses FormatGo.
+func FormatGo() FormatOption {
+	return func(f *formatter) { f.format = formatGo }
+}
+
 // format prints a pretty representation of m to the fs io.Writer. The format character c
 // specifies the numerical representation of elements; valid values are those for float64
 // specified in the fmt package, with their associated flags. In addition to this, a space
@@ -196,8 +274,14 @@ func formatMATLAB(m Matrix, prefix string, _ int, _ byte, squeeze bool, fs fmt.S
 	rows, cols := m.Dims()
 
 	prec, pOk := fs.Precision()
-	width, _ := fs.Width()
+	width, wOk := fs.Width()
 	if !fs.Flag('#') {
+		if !pOk {
+			prec = -1
+		}
+		if !wOk {
+			width = -1
+		}
 		switch c {
 		case 'v', 'e', 'E', 'f', 'F', 'g', 'G':
 		default:
@@ -298,6 +382,309 @@ func formatMATLAB(m Matrix, prefix string, _ int, _ byte, squeeze bool, fs fmt.S
 	}
 }
 
+// formatPython prints a NumPy representation of m to the fs io.Writer. The format character c
+// specifies the numerical representation of elements; valid values are those for float64
+// specified in the fmt package, with their associated flags.
+// If squeeze is true, column widths are determined on a per-column basis.
+//
+// formatPython will not provide Go syntax output.
+func formatPython(m Matrix, prefix string, _ int, _ byte, squeeze bool, fs fmt.State, c rune) {
+	cells, widths, ok := literalCells(m, squeeze, fs, c, literal{nan: "np.nan", inf: "np.inf", ninf: "-np.inf"})
+	if !ok {
+		return
+	}
+	rows, cols := m.Dims()
+	if _, isVec := m.(Vector); isVec {
+		fmt.Fprint(fs, "np.array([")
+		for i := 0; i < rows; i++ {
+			if i != 0 {
+				fmt.Fprint(fs, ", ")
+			}
+			writeCell(fs, cells[i][0], widths[0])
+		}
+		fmt.Fprint(fs, "])")
+		return
+	}
+	fmt.Fprint(fs, "np.array([")
+	for i := 0; i < rows; i++ {
+		if i != 0 {
+			if fs.Flag('#') {
+				fmt.Fprint(fs, ",\n"+prefix+"          ")
+			} else {
+				fmt.Fprint(fs, ", ")
+			}
+		}
+		fmt.Fprint(fs, "[")
+		for j := 0; j < cols; j++ {
+			if j != 0 {
+				fmt.Fprint(fs, ", ")
+			}
+			writeCell(fs, cells[i][j], widths[j])
+		}
+		fmt.Fprint(fs, "]")
+	}
+	fmt.Fprint(fs, "])")
+}
+
+// formatJulia prints a Julia representation of m to the fs io.Writer. The format character c
+// specifies the numerical representation of elements; valid values are those for float64
+// specified in the fmt package, with their associated flags.
+// If squeeze is true, column widths are determined on a per-column basis.
+//
+// formatJulia will not provide Go syntax output.
+func formatJulia(m Matrix, prefix string, _ int, _ byte, squeeze bool, fs fmt.State, c rune) {
+	cells, widths, ok := literalCells(m, squeeze, fs, c, literal{nan: "NaN", inf: "Inf", ninf: "-Inf"})
+	if !ok {
+		return
+	}
+	rows, cols := m.Dims()
+	fmt.Fprint(fs, "[")
+	for i := 0; i < rows; i++ {
+		if i != 0 {
+			if fs.Flag('#') {
+				fmt.Fprint(fs, "\n"+prefix+" ")
+			} else {
+				fmt.Fprint(fs, "; ")
+			}
+		}
+		for j := 0; j < cols; j++ {
+			if j != 0 {
+				fmt.Fprint(fs, " ")
+			}
+			writeCell(fs, cells[i][j], widths[j])
+		}
+	}
+	fmt.Fprint(fs, "]")
+}
+
+// formatLaTeX prints a LaTeX representation of m in the environment env to the fs io.Writer.
+// The format character c specifies the numerical representation of elements; valid values
+// are those for float64 specified in the fmt package, with their associated flags.
+// If squeeze is true, column widths are determined on a per-column basis.
+//
+// formatLaTeX will not provide Go syntax output.
+func formatLaTeX(env string, m Matrix, prefix string, _ int, dot byte, squeeze bool, fs fmt.State, c rune) {
+	lit := literal{nan: `\mathrm{NaN}`, inf: `\infty`, ninf: `-\infty`}
+	if fs.Flag(' ') || isSparse(m) {
+		lit.zero = string(dot)
+	}
+	cells, widths, ok := literalCells(m, squeeze, fs, c, lit)
+	if !ok {
+		return
+	}
+	rows, cols := m.Dims()
+	multiline := fs.Flag('#')
+	fmt.Fprintf(fs, `\begin{%s}`, env)
+	for i := 0; i < rows; i++ {
+		switch {
+		case multiline:
+			fmt.Fprint(fs, "\n"+prefix+"  ")
+		case i != 0:
+			fmt.Fprint(fs, " ")
+		}
+		for j := 0; j < cols; j++ {
+			if j != 0 {
+				fmt.Fprint(fs, " & ")
+			}
+			writeCell(fs, cells[i][j], widths[j])
+		}
+		if i < rows-1 {
+			fmt.Fprint(fs, ` \\`)
+		}
+	}
+	if multiline {
+		fmt.Fprint(fs, "\n"+prefix)
+	}
+	fmt.Fprintf(fs, `\end{%s}`, env)
+}
+
+// formatMarkdown prints a Markdown table representation of m to the fs io.Writer.
+// The format character c specifies the numerical representation of elements; valid values
+// are those for float64 specified in the fmt package, with their associated flags.
+// If squeeze is true, column widths are determined on a per-column basis.
+//
+// formatMarkdown will not provide Go syntax output.
+func formatMarkdown(m Matrix, prefix string, _ int, dot byte, squeeze bool, fs fmt.State, c rune) {
+	lit := literal{nan: "NaN", inf: "+Inf", ninf: "-Inf"}
+	if fs.Flag(' ') || isSparse(m) {
+		lit.zero = string(dot)
+	}
+	cells, widths, ok := literalCells(m, squeeze, fs, c, lit)
+	if !ok {
+		return
+	}
+	rows, cols := m.Dims()
+	header := make([]string, cols)
+	for j := range header {
+		header[j] = strconv.Itoa(j)
+		if fs.Flag('#') {
+			widths[j] = max(widths[j], max(len(header[j]), 3))
+		}
+	}
+	writeRow := func(row []string) {
+		fmt.Fprint(fs, "|")
+		for j, cell := range row {
+			fmt.Fprint(fs, " ")
+			writeCell(fs, cell, widths[j])
+			fmt.Fprint(fs, " |")
+		}
+	}
+	writeRow(header)
+	fmt.Fprint(fs, "\n"+prefix+"|")
+	for j := range header {
+		fmt.Fprint(fs, strings.Repeat("-", max(widths[j], 2)+1)+":|")
+	}
+	for i := 0; i < rows; i++ {
+		fmt.Fprint(fs, "\n"+prefix)
+		writeRow(cells[i])
+	}
+}
+
+// isSparse returns whether m is one of the sparse matrix types.
+func isSparse(m Matrix) bool {
+	switch m.(type) {
+	case *Sparse, *CSR, *CSC:
+		return true
+	}
+	return false
+}
+
+// formatGo prints Go syntax constructing m to the fs io.Writer.
+//
+// formatGo ignores the precision and width of fs so that the output round-trips exactly.
+func formatGo(m Matrix, prefix string, _ int, _ byte, _ bool, fs fmt.State, c rune) {
+	rows, cols := m.Dims()
+	if c != 'v' {
+		fmt.Fprintf(fs, "%%!%c(%T=Dims(%d, %d))", c, m, rows, cols)
+		return
+	}
+	multiline := fs.Flag('#')
+	at := m.At
+	if _, isVec := m.(Vector); isVec {
+		// Vectors are written on a single row.
+		fmt.Fprintf(fs, "lap.NewDenseVector(%d, []float64{", rows)
+		at = func(_, j int) float64 { return m.At(j, 0) }
+		rows, cols = 1, rows
+	} else {
+		fmt.Fprintf(fs, "lap.NewDenseMatrix(%d, %d, []float64{", rows, cols)
+	}
+	if rows == 0 || cols == 0 {
+		multiline = false
+	}
+	buf := make([]byte, 0, 32)
+	for i := 0; i < rows; i++ {
+		if multiline {
+			fmt.Fprint(fs, "\n"+prefix+"\t")
+		}
+		for j := 0; j < cols; j++ {
+			buf = appendGoFloat(buf[:0], at(i, j))
+			switch {
+			case j < cols-1:
+				buf = append(buf, ", "...)
+			case multiline:
+				buf = append(buf, ',')
+			case i < rows-1:
+				buf = append(buf, ", "...)
+			}
+			fs.Write(buf)
+		}
+	}
+	if multiline {
+		fmt.Fprint(fs, "\n"+prefix)
+	}
+	fmt.Fprint(fs, "})")
+}
+
+// appendGoFloat appends the Go expression for v to buf.
+func appendGoFloat(buf []byte, v float64) []byte {
+	switch {
+	case math.IsNaN(v):
+		return append(buf, "math.NaN()"...)
+	case math.IsInf(v, 1):
+		return append(buf, "math.Inf(1)"...)
+	case math.IsInf(v, -1):
+		return append(buf, "math.Inf(-1)"...)
+	case v == 0 && math.Signbit(v):
+		return append(buf, "math.Copysign(0, -1)"...)
+	}
+	return strconv.AppendFloat(buf, v, 'g', -1, 64)
+}
+
+// literal holds the representation of special values in an output syntax.
+type literal struct {
+	nan, inf, ninf string
+	// zero replaces zero elements if not empty.
+	zero string
+}
+
+// literalCells returns the elements of m formatted with the verb c and the flags and
+// precision of fs, with special values replaced as specified by lit. The returned
+// widths hold the width of each column: the fs width, or if the '#' flag is set, at
+// least the widest element of the column if squeeze is true, or of the matrix otherwise.
+// If c is not a valid verb an error is written to fs and ok is false.
+func literalCells(m Matrix, squeeze bool, fs fmt.State, c rune, lit literal) (cells [][]string, widths []int, ok bool) {
+	rows, cols := m.Dims()
+	switch c {
+	case 'v', 'e', 'E', 'f', 'F', 'g', 'G':
+	default:
+		fmt.Fprintf(fs, "%%!%c(%T=Dims(%d, %d))", c, m, rows, cols)
+		return nil, nil, false
+	}
+	prec, pOk := fs.Precision()
+	if !pOk {
+		prec = -1
+	}
+	width, _ := fs.Width()
+	format := fmtString(fs, c, prec, -1)
+	// The ' ' flag is not a sign flag for these syntaxes.
+	format = strings.Replace(format, " ", "", 1)
+	cells = make([][]string, rows)
+	widths = make([]int, cols)
+	var maxWidth int
+	for i := range cells {
+		cells[i] = make([]string, cols)
+		for j := range cells[i] {
+			v := m.At(i, j)
+			switch {
+			case v == 0 && lit.zero != "":
+				cells[i][j] = lit.zero
+			case math.IsNaN(v):
+				cells[i][j] = lit.nan
+			case math.IsInf(v, 1):
+				cells[i][j] = lit.inf
+			case math.IsInf(v, -1):
+				cells[i][j] = lit.ninf
+			default:
+				cells[i][j] = fmt.Sprintf(format, v)
+			}
+			widths[j] = max(widths[j], len(cells[i][j]))
+			maxWidth = max(maxWidth, widths[j])
+		}
+	}
+	for j := range widths {
+		switch {
+		case !fs.Flag('#'):
+			widths[j] = width
+		case squeeze:
+			widths[j] = max(width, widths[j])
+		default:
+			widths[j] = max(width, maxWidth)
+		}
+	}
+	return cells, widths, true
+}
+
+// writeCell writes s to fs padded with spaces to width, left aligned if the
+// '-' flag is set and right aligned otherwise.
+func writeCell(fs fmt.State, s string, width int) {
+	pad := strings.Repeat(" ", max(width-len(s), 0))
+	if fs.Flag('-') {
+		fmt.Fprint(fs, s, pad)
+	} else {
+		fmt.Fprint(fs, pad, s)
+	}
+}
+
 // This is horrible, but it's what we have.
 func fmtString(fs fmt.State, c rune, prec, width int) string {
 	var b strings.Builder
@@ -327,6 +714,13 @@ func max(a, b int) int {
 	return b
 }
 
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 type widther interface {
 	width(i int) int
 	setWidth(i, w int)
@@ -0,0 +1,286 @@
+package lap
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	big := NewDenseMatrix(10, 10, nil)
+	big.DoSet(func(i, j int, _ float64) float64 { return float64(10*i + j) })
+	sparse := NewDenseMatrix(2, 3, []float64{0, 1, 100, 0, 1000, 0})
+	for _, test := range []struct {
+		format string
+		m      fmt.Formatter
+		want   string
+	}{
+		{
+			format: "%v",
+			m:      Formatted(NewDenseMatrix(2, 2, []float64{1, 2, 3, 4})),
+			want:   "⎡1  2⎤\n⎣3  4⎦",
+		},
+		{
+			format: "%v",
+			m:      Formatted(big, Excerpt(3)),
+			want: `Dims(10, 10)
+⎡ 0   1   2  ...  ...   7   8   9⎤
+⎢10  11  12            17  18  19⎥
+⎢20  21  22            27  28  29⎥
+ .
+ .
+ .
+⎢70  71  72            77  78  79⎥
+⎢80  81  82            87  88  89⎥
+⎣90  91  92  ...  ...  97  98  99⎦`,
+		},
+		{
+			format: "%v",
+			m:      Formatted(big, Excerpt(2), Prefix("  ")),
+			want: `Dims(10, 10)
+  ⎡ 0   1  ...  ...   8   9⎤
+  ⎢10  11            18  19⎥
+   .
+   .
+   .
+  ⎢80  81            88  89⎥
+  ⎣90  91  ...  ...  98  99⎦`,
+		},
+		{
+			// An excerpt larger than the matrix prints all elements.
+			format: "%v",
+			m:      Formatted(NewDenseMatrix(2, 2, []float64{1, 2, 3, 4}), Excerpt(5)),
+			want:   "⎡1  2⎤\n⎣3  4⎦",
+		},
+		{
+			format: "%v",
+			m:      Formatted(NewDenseMatrix(1, 10, nil), Excerpt(2)),
+			want:   "Dims(1, 10)\n[0  0  ...  ...  0  0]",
+		},
+		{
+			format: "%v",
+			m:      Formatted(NewDenseVector(10, nil), Excerpt(1)),
+			want:   "Dims(10, 1)\n⎡0⎤\n .\n .\n .\n⎣0⎦",
+		},
+		{
+			format: "%.1f",
+			m:      Formatted(big, Excerpt(1)),
+			want:   "Dims(10, 10)\n⎡ 0.0  ...  ...   9.0⎤\n .\n .\n .\n⎣90.0  ...  ...  99.0⎦",
+		},
+		{
+			format: "%v",
+			m:      Formatted(sparse, Prefix("x = ")),
+			want:   "⎡   0     1   100⎤\nx = ⎣   0  1000     0⎦",
+		},
+		{
+			format: "% v",
+			m:      Formatted(sparse),
+			want:   "⎡   .     1   100⎤\n⎣   .  1000     .⎦",
+		},
+		{
+			format: "% v",
+			m:      Formatted(sparse, Squeeze(), DotByte('*')),
+			want:   "⎡*     1  100⎤\n⎣*  1000    *⎦",
+		},
+		{
+			format: "%v",
+			m:      Formatted(sparse, Squeeze()),
+			want:   "⎡0     1  100⎤\n⎣0  1000    0⎦",
+		},
+	} {
+		got := fmt.Sprintf(test.format, test.m)
+		if got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.format, got, test.want)
+		}
+	}
+}
+
+func TestFormatPythonJulia(t *testing.T) {
+	m := NewDenseMatrix(2, 3, []float64{1, -2.5, math.NaN(), math.Inf(1), 100, math.Inf(-1)})
+	v := NewDenseVector(3, []float64{1, 2, 3})
+	for _, test := range []struct {
+		format string
+		m      fmt.Formatter
+		want   string
+	}{
+		{"%v", Formatted(m, FormatPython()), "np.array([[1, -2.5, np.nan], [np.inf, 100, -np.inf]])"},
+		{"%.2f", Formatted(m, FormatPython()), "np.array([[1.00, -2.50, np.nan], [np.inf, 100.00, -np.inf]])"},
+		{"%5v", Formatted(v, FormatPython()), "np.array([    1,     2,     3])"},
+		{"%v", Formatted(NewDenseMatrix(3, 1, []float64{1, 2, 3}), FormatPython()), "np.array([[1], [2], [3]])"},
+		{
+			"%#.3e", Formatted(m, FormatPython(), Prefix("\t")),
+			"np.array([[ 1.000e+00, -2.500e+00,     np.nan],\n\t          [    np.inf,  1.000e+02,    -np.inf]])",
+		},
+		{
+			"%#v", Formatted(m, FormatPython(), Squeeze()),
+			"np.array([[     1, -2.5,  np.nan],\n          [np.inf,  100, -np.inf]])",
+		},
+		{"%v", Formatted(m, FormatJulia()), "[1 -2.5 NaN; Inf 100 -Inf]"},
+		{"%+.1f", Formatted(m, FormatJulia()), "[+1.0 -2.5 NaN; Inf +100.0 -Inf]"},
+		{"%v", Formatted(v, FormatJulia()), "[1; 2; 3]"},
+		{"%#-v", Formatted(m, FormatJulia()), "[1    -2.5 NaN \n Inf  100  -Inf]"},
+		{"%#v", Formatted(m, FormatJulia(), Squeeze()), "[  1 -2.5  NaN\n Inf  100 -Inf]"},
+		{"%d", Formatted(m, FormatJulia()), "%!d(*lap.DenseM=Dims(2, 3))"},
+	} {
+		got := fmt.Sprintf(test.format, test.m)
+		if got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.format, got, test.want)
+		}
+	}
+}
+
+func TestFormatLaTeXMarkdown(t *testing.T) {
+	m := NewDenseMatrix(2, 3, []float64{1, -2.5, math.NaN(), math.Inf(1), 0, math.Inf(-1)})
+	s := NewSparse(2, 2)
+	s.Set(0, 0, 1.5)
+	s.Set(1, 1, -2)
+	for _, test := range []struct {
+		format string
+		m      fmt.Formatter
+		want   string
+	}{
+		{
+			"%v", Formatted(m, FormatLaTeX("")),
+			`\begin{bmatrix}1 & -2.5 & \mathrm{NaN} \\ \infty & 0 & -\infty\end{bmatrix}`,
+		},
+		{
+			"% .2f", Formatted(m, FormatLaTeX("pmatrix")),
+			`\begin{pmatrix}1.00 & -2.50 & \mathrm{NaN} \\ \infty & . & -\infty\end{pmatrix}`,
+		},
+		{
+			"%#v", Formatted(m, FormatLaTeX("vmatrix"), Squeeze(), Prefix("  ")),
+			"\\begin{vmatrix}\n         1 & -2.5 & \\mathrm{NaN} \\\\\n    \\infty &    0 &      -\\infty\n  \\end{vmatrix}",
+		},
+		{
+			"%#v", Formatted(s, FormatLaTeX(""), DotByte('*')),
+			"\\begin{bmatrix}\n  1.5 &   * \\\\\n    * &  -2\n\\end{bmatrix}",
+		},
+		{
+			"%v", Formatted(NewCSR(s), FormatLaTeX("")),
+			`\begin{bmatrix}1.5 & . \\ . & -2\end{bmatrix}`,
+		},
+		{
+			"%v", Formatted(m, FormatMarkdown()),
+			"| 0 | 1 | 2 |\n|---:|---:|---:|\n| 1 | -2.5 | NaN |\n| +Inf | 0 | -Inf |",
+		},
+		{
+			"%#.1e", Formatted(m, FormatMarkdown(), Prefix("> ")),
+			"|        0 |        1 |        2 |\n> |---------:|---------:|---------:|\n> |  1.0e+00 | -2.5e+00 |      NaN |\n> |     +Inf |  0.0e+00 |     -Inf |",
+		},
+		{
+			"%#v", Formatted(s, FormatMarkdown()),
+			"|   0 |   1 |\n|----:|----:|\n| 1.5 |   . |\n|   . |  -2 |",
+		},
+		{
+			"% v", Formatted(NewDenseMatrix(1, 2, []float64{0, 3}), FormatMarkdown(), DotByte('-')),
+			"| 0 | 1 |\n|---:|---:|\n| - | 3 |",
+		},
+	} {
+		got := fmt.Sprintf(test.format, test.m)
+		if got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.format, got, test.want)
+		}
+	}
+}
+
+func TestFormatGo(t *testing.T) {
+	m := NewDenseMatrix(2, 3, []float64{1.0 / 3, -2.5, math.NaN(), math.Inf(1), math.Copysign(0, -1), 1e300})
+	v := NewDenseVector(3, []float64{1, 2, math.Inf(-1)})
+	for _, test := range []struct {
+		format string
+		m      fmt.Formatter
+		want   string
+	}{
+		{
+			"%v", Formatted(m, FormatGo()),
+			"lap.NewDenseMatrix(2, 3, []float64{0.3333333333333333, -2.5, math.NaN(), math.Inf(1), math.Copysign(0, -1), 1e+300})",
+		},
+		{
+			"%#v", Formatted(m),
+			"lap.NewDenseMatrix(2, 3, []float64{\n\t0.3333333333333333, -2.5, math.NaN(),\n\tmath.Inf(1), math.Copysign(0, -1), 1e+300,\n})",
+		},
+		{
+			"%#v", Formatted(m, Prefix("\t")),
+			"lap.NewDenseMatrix(2, 3, []float64{\n\t\t0.3333333333333333, -2.5, math.NaN(),\n\t\tmath.Inf(1), math.Copysign(0, -1), 1e+300,\n\t})",
+		},
+		{"%v", Formatted(v, FormatGo()), "lap.NewDenseVector(3, []float64{1, 2, math.Inf(-1)})"},
+		{"%#v", Formatted(v), "lap.NewDenseVector(3, []float64{\n\t1, 2, math.Inf(-1),\n})"},
+		{"%#v", Formatted(NewDenseMatrix(0, 0, nil)), "lap.NewDenseMatrix(0, 0, []float64{})"},
+		{"%.2f", Formatted(m, FormatGo()), "%!f(*lap.DenseM=Dims(2, 3))"},
+	} {
+		got := fmt.Sprintf(test.format, test.m)
+		if got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.format, got, test.want)
+		}
+	}
+
+	// The output is valid Go that evaluates to exactly the same values.
+	rng := rand.New(rand.NewSource(1))
+	data := randomSlice(rng, 20)
+	data[3] = math.SmallestNonzeroFloat64
+	data[7] = -math.MaxFloat64
+	r := NewDenseMatrix(4, 5, data)
+	for _, verb := range []string{"%v", "%#v"} {
+		src := fmt.Sprintf(verb, Formatted(r, FormatGo()))
+		got, err := evalGoMatrix(src)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", verb, err, src)
+		}
+		if !matrixEqual(got, r) {
+			t.Errorf("%s: round trip mismatch", verb)
+		}
+	}
+}
+
+// evalGoMatrix evaluates the lap.NewDenseMatrix call expression src.
+func evalGoMatrix(src string) (*DenseM, error) {
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 3 {
+		return nil, fmt.Errorf("not a constructor call")
+	}
+	dim := func(e ast.Expr) (int, error) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok {
+			return 0, fmt.Errorf("bad dimension")
+		}
+		return strconv.Atoi(lit.Value)
+	}
+	r, err := dim(call.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	c, err := dim(call.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	comp, ok := call.Args[2].(*ast.CompositeLit)
+	if !ok {
+		return nil, fmt.Errorf("data is not a composite literal")
+	}
+	var data []float64
+	for _, e := range comp.Elts {
+		sign := 1.0
+		if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.SUB {
+			sign, e = -1, u.X
+		}
+		lit, ok := e.(*ast.BasicLit)
+		if !ok {
+			return nil, fmt.Errorf("unsupported element %T", e)
+		}
+		v, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, sign*v)
+	}
+	return NewDenseMatrix(r, c, data), nil
+}
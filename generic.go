@@ -0,0 +1,358 @@
+package lap
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Float is the constraint satisfied by the element types of the generic
+// matrix types. float32 is often the native floating point type on
+// microcontrollers and wasm targets, where it is faster than float64.
+//
+// The generic API covers dense storage, Mul, Add and Norm through NormOf, and
+// the factorizations through LUOf, CholeskyOf, QROf, SVDOf, EigenSymOf and
+// EigenOf, which share their implementation with the float64 types. Cholesky
+// Update and Downdate, the sparse types and the iterative solvers are only
+// available for float64.
+type Float interface {
+	~float32 | ~float64
+}
+
+// MatrixOf is the generic counterpart of Matrix with elements of type T.
+// Any Matrix is also a MatrixOf[float64].
+type MatrixOf[T Float] interface {
+	At(i, j int) T
+	Dims() (r, c int)
+}
+
+// VectorOf is the generic counterpart of Vector with elements of type T.
+// Any Vector is also a VectorOf[float64].
+type VectorOf[T Float] interface {
+	MatrixOf[T]
+	AtVec(i int) T
+	Len() int
+}
+
+// DenseMOf represents a row major storage matrix with elements of type T.
+// DenseMOf[float64] implements Matrix so it can be used with the rest of the
+// package, other element types are limited to the generic API described at Float.
+type DenseMOf[T Float] struct {
+	data   []T
+	stride int
+	r, c   int
+}
+
+// NewDenseMatrixOf produces a new (rxc) matrix backed by contiguous data.
+// data may be nil, in which case an array of zeros is returned, otherwise
+// it must have length r*c.
+func NewDenseMatrixOf[T Float](r, c int, data []T) *DenseMOf[T] {
+	if data == nil {
+		data = make([]T, r*c)
+	}
+	if len(data) != r*c {
+		panic(ErrDim)
+	}
+	return &DenseMOf[T]{
+		data:   data,
+		r:      r,
+		c:      c,
+		stride: c,
+	}
+}
+
+// Dims returns the dimensions of the matrix.
+func (d *DenseMOf[T]) Dims() (int, int) { return d.r, d.c }
+
+// At returns d's element at ith row, jth column.
+func (d *DenseMOf[T]) At(i, j int) T {
+	if i < 0 || i >= d.r {
+		panic(ErrRowAccess)
+	} else if j < 0 || j >= d.c {
+		panic(ErrColAccess)
+	}
+	return d.data[i*d.stride+j]
+}
+
+// Set sets d's element at ith row, jth column to v.
+func (d *DenseMOf[T]) Set(i, j int, v T) {
+	if i < 0 || i >= d.r {
+		panic(ErrRowAccess)
+	} else if j < 0 || j >= d.c {
+		panic(ErrColAccess)
+	}
+	d.data[i*d.stride+j] = v
+}
+
+// Copy copies the elements of A into the receiver. If the receiver is not
+// initialized then the backing array is allocated automatically.
+func (d *DenseMOf[T]) Copy(A MatrixOf[T]) {
+	r, c := A.Dims()
+	if d.data == nil {
+		*d = *NewDenseMatrixOf[T](r, c, nil)
+	}
+	if r != d.r || c != d.c {
+		panic(ErrDim)
+	}
+	if Ad, ok := A.(*DenseMOf[T]); ok {
+		for i := 0; i < r; i++ {
+			copy(d.data[i*d.stride:i*d.stride+c], Ad.data[i*Ad.stride:])
+		}
+		return
+	}
+	for i := 0; i < d.r; i++ {
+		for j := 0; j < d.c; j++ {
+			d.data[i*d.stride+j] = A.At(i, j)
+		}
+	}
+}
+
+// Mul computes the matrix-matrix product C = AB for (nxm) matrix A and (mxp)
+// matrix B, storing the result in (nxp) matrix C.
+func (C *DenseMOf[T]) Mul(A, B MatrixOf[T]) {
+	n, m := A.Dims()
+	mB, p := B.Dims()
+	if C.data == nil {
+		*C = *NewDenseMatrixOf[T](n, p, nil)
+	}
+	nC, pC := C.Dims()
+	if m != mB || nC != n || pC != p {
+		panic(ErrDim)
+	}
+	if aliasedOf(C.data, denseData(A)) || aliasedOf(C.data, denseData(B)) {
+		panic(ErrAliasedData)
+	}
+	for i := 0; i < n; i++ {
+		ridx := i * C.stride
+		for j := 0; j < p; j++ {
+			var tmp T
+			for k := 0; k < m; k++ {
+				tmp += A.At(i, k) * B.At(k, j)
+			}
+			C.data[ridx+j] = tmp
+		}
+	}
+}
+
+// Add stores the elementwise addition A+B in C.
+func (C *DenseMOf[T]) Add(A, B MatrixOf[T]) {
+	rA, cA := A.Dims()
+	rB, cB := B.Dims()
+	if C.data == nil {
+		*C = *NewDenseMatrixOf[T](rA, cA, nil)
+	}
+	r, c := C.Dims()
+	if rA != r || rB != r || cA != c || cB != c {
+		panic(ErrDim)
+	}
+	for i := 0; i < r; i++ {
+		ridx := i * C.stride
+		for j := 0; j < c; j++ {
+			C.data[ridx+j] = A.At(i, j) + B.At(i, j)
+		}
+	}
+}
+
+// Sub stores the elementwise difference A-B in C.
+func (C *DenseMOf[T]) Sub(A, B MatrixOf[T]) {
+	rA, cA := A.Dims()
+	rB, cB := B.Dims()
+	if C.data == nil {
+		*C = *NewDenseMatrixOf[T](rA, cA, nil)
+	}
+	r, c := C.Dims()
+	if rA != r || rB != r || cA != c || cB != c {
+		panic(ErrDim)
+	}
+	for i := 0; i < r; i++ {
+		ridx := i * C.stride
+		for j := 0; j < c; j++ {
+			C.data[ridx+j] = A.At(i, j) - B.At(i, j)
+		}
+	}
+}
+
+// Scale multiplies the elements of A by f, placing the result in the receiver.
+func (C *DenseMOf[T]) Scale(f T, A MatrixOf[T]) {
+	rA, cA := A.Dims()
+	if C.data == nil {
+		*C = *NewDenseMatrixOf[T](rA, cA, nil)
+	}
+	r, c := C.Dims()
+	if rA != r || cA != c {
+		panic(ErrDim)
+	}
+	for i := 0; i < r; i++ {
+		ridx := i * C.stride
+		for j := 0; j < c; j++ {
+			C.data[ridx+j] = f * A.At(i, j)
+		}
+	}
+}
+
+// RowView returns a vector that shares the data of the ith row of A.
+func (A *DenseMOf[T]) RowView(i int) *DenseVOf[T] {
+	if i >= A.r || i < 0 {
+		panic(ErrRowAccess)
+	}
+	return &DenseVOf[T]{
+		data: A.data[i*A.stride : i*A.stride+A.c],
+	}
+}
+
+// ColView returns a vector that shares the data of the jth column of A.
+func (A *DenseMOf[T]) ColView(j int) *DenseVOf[T] {
+	if j >= A.c || j < 0 {
+		panic(ErrColAccess)
+	}
+	return &DenseVOf[T]{
+		data:        A.data[j:],
+		incMinusOne: A.stride - 1,
+	}
+}
+
+// DenseVOf is a vector with elements of type T. DenseVOf[float64]
+// implements Vector so it can be used with the rest of the package.
+type DenseVOf[T Float] struct {
+	data        []T
+	incMinusOne int
+}
+
+// NewDenseVectorOf returns a vector of length n with data. If data is nil it is
+// automatically allocated.
+func NewDenseVectorOf[T Float](n int, data []T) *DenseVOf[T] {
+	if data == nil {
+		data = make([]T, n)
+	}
+	if len(data) != n {
+		panic(ErrDim)
+	}
+	return &DenseVOf[T]{
+		data: data,
+	}
+}
+
+func (v *DenseVOf[T]) Dims() (int, int) { return v.Len(), 1 }
+
+func (v *DenseVOf[T]) At(i, j int) T {
+	if j != 0 {
+		panic(ErrColAccess)
+	}
+	return v.AtVec(i)
+}
+
+func (v *DenseVOf[T]) Set(i, j int, f T) {
+	if j != 0 {
+		panic(ErrColAccess)
+	}
+	v.SetVec(i, f)
+}
+
+func (v *DenseVOf[T]) Len() int {
+	inc := v.incMinusOne + 1
+	return (len(v.data) + inc - 1) / inc
+}
+
+func (v *DenseVOf[T]) AtVec(i int) T {
+	return v.data[i*(v.incMinusOne+1)]
+}
+
+func (v *DenseVOf[T]) SetVec(i int, f T) {
+	v.data[i*(v.incMinusOne+1)] = f
+}
+
+// CopyVec makes a copy of elements of a into the receiver and returns the amount
+// of elements copied. If the receiver has not been initialized then a vector is allocated.
+func (v *DenseVOf[T]) CopyVec(a VectorOf[T]) int {
+	n := a.Len()
+	if v.data == nil {
+		*v = *NewDenseVectorOf[T](n, nil)
+	}
+	if n != v.Len() {
+		panic(ErrDim)
+	}
+	for i := 0; i < n; i++ {
+		v.SetVec(i, a.AtVec(i))
+	}
+	return n
+}
+
+// AddVec adds the vectors a+b element-wise, placing the result in the receiver.
+func (v *DenseVOf[T]) AddVec(a, b VectorOf[T]) {
+	n := a.Len()
+	if v.data == nil {
+		*v = *NewDenseVectorOf[T](n, nil)
+	}
+	if n != b.Len() || n != v.Len() {
+		panic(ErrDim)
+	}
+	for i := 0; i < n; i++ {
+		v.SetVec(i, a.AtVec(i)+b.AtVec(i))
+	}
+}
+
+// MulVec computes A * b. The result is stored into the receiver.
+// MulVec panics if the number of columns in A does not equal the length of b.
+func (v *DenseVOf[T]) MulVec(A MatrixOf[T], b VectorOf[T]) {
+	n := b.Len()
+	m, c := A.Dims()
+	if c != n {
+		panic(ErrDim)
+	}
+	if v.data == nil {
+		*v = *NewDenseVectorOf[T](m, nil)
+	} else if aliasedOf(v.data, denseData[T](b)) || aliasedOf(v.data, denseData(A)) {
+		panic(ErrAliasedData)
+	}
+	if m != v.Len() {
+		panic(ErrDim)
+	}
+	for i := 0; i < m; i++ {
+		var tmp T
+		for j := 0; j < n; j++ {
+			tmp += A.At(i, j) * b.AtVec(j)
+		}
+		v.SetVec(i, tmp)
+	}
+}
+
+// denseData returns the backing data of m if it is a generic dense type.
+func denseData[T Float](m MatrixOf[T]) []T {
+	switch D := m.(type) {
+	case *DenseMOf[T]:
+		return D.data
+	case *DenseVOf[T]:
+		return D.data
+	}
+	return nil
+}
+
+// aliasedOf reports whether the memory of a and b overlaps.
+func aliasedOf[T Float](a, b []T) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	size := unsafe.Sizeof(a[0])
+	pa := uintptr(unsafe.Pointer(&a[0]))
+	pb := uintptr(unsafe.Pointer(&b[0]))
+	return pa < pb+uintptr(len(b))*size && pb < pa+uintptr(len(a))*size
+}
+
+// epsilonOf returns the machine epsilon of T.
+func epsilonOf[T Float]() float64 {
+	if unsafe.Sizeof(T(0)) == 4 {
+		return 0x1p-23
+	}
+	return epsilon
+}
+
+func absOf[T Float](x T) T {
+	return T(math.Abs(float64(x)))
+}
+
+func sqrtOf[T Float](x T) T {
+	return T(math.Sqrt(float64(x)))
+}
+
+func hypotOf[T Float](p, q T) T {
+	return T(math.Hypot(float64(p), float64(q)))
+}
@@ -0,0 +1,344 @@
+package lap
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// float32Dense returns a float32 copy of A.
+func float32Dense(A Matrix) *DenseMOf[float32] {
+	r, c := A.Dims()
+	d := NewDenseMatrixOf[float32](r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			d.Set(i, j, float32(A.At(i, j)))
+		}
+	}
+	return d
+}
+
+// float64Dense returns a float64 copy of the float32 matrix A.
+func float64Dense(A MatrixOf[float32]) *DenseM {
+	r, c := A.Dims()
+	d := NewDenseMatrix(r, c, nil)
+	d.DoSet(func(i, j int, _ float64) float64 { return float64(A.At(i, j)) })
+	return d
+}
+
+// float32EqualTol reports whether the float32 matrix a is within tol of the
+// float64 matrix b relative to the magnitude of the elements of b.
+func float32EqualTol(a MatrixOf[float32], b Matrix, tol float64) bool {
+	r, c := a.Dims()
+	rb, cb := b.Dims()
+	if r != rb || c != cb {
+		return false
+	}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			want := b.At(i, j)
+			if math.Abs(float64(a.At(i, j))-want) > tol*math.Max(1, math.Abs(want)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestDenseMOf(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := NewDenseMatrix(4, 3, randomSlice(rng, 12))
+	B := NewDenseMatrix(3, 5, randomSlice(rng, 15))
+	var AB DenseM
+	AB.Mul(A, B)
+	var AB32 DenseMOf[float32]
+	AB32.Mul(float32Dense(A), float32Dense(B))
+	if !float32EqualTol(&AB32, &AB, 1e-6) {
+		t.Errorf("float32 Mul: got %v, want %v", AB32.data, AB.data)
+	}
+
+	var sum DenseM
+	sum.Add(A, A)
+	var sum32 DenseMOf[float32]
+	sum32.Add(float32Dense(A), float32Dense(A))
+	if !float32EqualTol(&sum32, &sum, 1e-6) {
+		t.Errorf("float32 Add: got %v, want %v", sum32.data, sum.data)
+	}
+
+	for _, norm := range []float64{1, 2, math.Inf(1)} {
+		got, want := NormOf[float32](float32Dense(A), norm), Norm(A, norm)
+		if !almostEqual(float64(got), want, 1e-6) {
+			t.Errorf("float32 norm %v: got %v, want %v", norm, got, want)
+		}
+	}
+
+	// DenseMOf[float64] and DenseVOf[float64] interoperate with the float64 API.
+	A64 := NewDenseMatrixOf[float64](4, 3, nil)
+	A64.Copy(A)
+	if !matrixEqual(A64, A) {
+		t.Error("DenseMOf[float64] copy of A is not equal to A")
+	}
+	var x DenseV
+	x.MulVec(A64, NewDenseVectorOf[float64](3, []float64{0, 1, 0}))
+	if !vectorEqual(&x, A.ColView(1)) {
+		t.Errorf("MulVec with DenseVOf[float64]: got %v", x.data)
+	}
+
+	var y DenseVOf[float32]
+	y.MulVec(float32Dense(A), NewDenseVectorOf[float32](3, []float32{1, 0, 0}))
+	if !float32EqualTol(&y, A.ColView(0), 1e-6) {
+		t.Errorf("float32 MulVec: got %v", y.data)
+	}
+}
+
+func TestGenericFactorizations(t *testing.T) {
+	const n = 6
+	rng := rand.New(rand.NewSource(1))
+	A := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	want := NewDenseVector(n, randomSlice(rng, n))
+	var b DenseV
+	b.MulVec(A, want)
+
+	var lu LUOf[float32]
+	var qr QROf[float32]
+	lu.Factorize(float32Dense(A))
+	if det := lu.Det(); !almostEqual(float64(det), Det(A), 1e-5) {
+		t.Errorf("float32 LU determinant %v, want %v", det, Det(A))
+	}
+	var x DenseVOf[float32]
+	if err := lu.SolveVec(&x, false, float32Dense(&b).ColView(0)); err != nil {
+		t.Fatal(err)
+	}
+	if !float32EqualTol(&x, want, 1e-3) {
+		t.Errorf("float32 LU solution %v, want %v", x.data, want.data)
+	}
+	var X DenseMOf[float32]
+	if err := lu.Solve(&X, false, float32Dense(&b)); err != nil {
+		t.Fatal(err)
+	}
+	if !float32EqualTol(&X, want, 1e-3) {
+		t.Errorf("float32 LU matrix solution %v, want %v", X.data, want.data)
+	}
+
+	// The float64 instantiation shares its implementation with LU.
+	var lu64 LUOf[float64]
+	lu64.Factorize(A)
+	var lu64ref LU
+	lu64ref.Factorize(A)
+	var x64, x64ref DenseVOf[float64]
+	lu64.SolveVec(&x64, true, &b)
+	var ref DenseV
+	lu64ref.SolveVec(&ref, true, &b)
+	x64ref.CopyVec(&ref)
+	if !matrixEqual(&x64, &x64ref) {
+		t.Errorf("LUOf[float64] solution %v differs from LU solution %v", x64.data, x64ref.data)
+	}
+
+	singular := NewDenseMatrixOf[float32](2, 2, []float32{1, 2, 2, 4})
+	lu.Factorize(singular)
+	if err := lu.SolveVec(&DenseVOf[float32]{}, false, NewDenseVectorOf[float32](2, nil)); !errors.Is(err, ErrSingular) {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+
+	var inv DenseMOf[float32]
+	lu.Factorize(float32Dense(A))
+	if err := lu.Inverse(&inv); err != nil {
+		t.Fatal(err)
+	}
+	var ident DenseMOf[float32]
+	ident.Mul(float32Dense(A), &inv)
+	if !float32EqualTol(&ident, Eye(n), 1e-4) {
+		t.Errorf("float32 LU inverse is not the inverse: %v", ident.data)
+	}
+	if logdet, sign := lu.LogDet(); !almostEqual(sign*math.Exp(logdet), Det(A), 1e-5) {
+		t.Errorf("float32 LU log determinant %v with sign %v, want determinant %v", logdet, sign, Det(A))
+	}
+	if cond := lu.Cond(); !almostEqual(cond, lu64ref.Cond(), 1e-4*lu64ref.Cond()) {
+		t.Errorf("float32 LU condition number %v, want %v", cond, lu64ref.Cond())
+	}
+
+	// The 6x6 Hilbert matrix has a condition number of about 3e7, which is
+	// beyond float32 precision but well within float64 precision.
+	hilbert := NewDenseMatrix(n, n, nil)
+	hilbert.DoSet(func(i, j int, _ float64) float64 { return 1 / float64(i+j+1) })
+	var cerr ConditionError
+	lu.Factorize(float32Dense(hilbert))
+	if err := lu.SolveVec(&DenseVOf[float32]{}, false, NewDenseVectorOf[float32](n, nil)); !errors.As(err, &cerr) || !errors.Is(err, ErrSingular) {
+		t.Errorf("expected ConditionError for float32 Hilbert matrix, got %v", err)
+	}
+	lu64.Factorize(hilbert)
+	if err := lu64.SolveVec(&DenseVOf[float64]{}, false, NewDenseVectorOf[float64](n, nil)); err != nil {
+		t.Errorf("float64 Hilbert matrix solve failed: %v", err)
+	}
+	qr.Factorize(float32Dense(hilbert))
+	if err := qr.SolveLeastSquares(&DenseVOf[float32]{}, NewDenseVectorOf[float32](n, nil)); !errors.As(err, &cerr) {
+		t.Errorf("expected ConditionError for float32 Hilbert least squares, got %v", err)
+	}
+
+	S := randomSPD(rng, n)
+	b.MulVec(S, want)
+	var ch CholeskyOf[float32]
+	if !ch.Factorize(float32Dense(S)) {
+		t.Fatal("float32 Cholesky failed on positive definite matrix")
+	}
+	if err := ch.SolveVec(&x, float32Dense(&b).ColView(0)); err != nil {
+		t.Fatal(err)
+	}
+	if !float32EqualTol(&x, want, 1e-3) {
+		t.Errorf("float32 Cholesky solution %v, want %v", x.data, want.data)
+	}
+	if det := ch.Det(); !almostEqual(float64(det), Det(S), 1e-4*math.Abs(Det(S))) {
+		t.Errorf("float32 Cholesky determinant %v, want %v", det, Det(S))
+	}
+	if logdet := ch.LogDet(); !almostEqual(logdet, math.Log(Det(S)), 1e-4) {
+		t.Errorf("float32 Cholesky log determinant %v, want %v", logdet, math.Log(Det(S)))
+	}
+	var luS LU
+	luS.Factorize(S)
+	if cond := ch.Cond(); !almostEqual(cond, luS.Cond(), 1e-3*luS.Cond()) {
+		t.Errorf("float32 Cholesky condition number %v, want %v", cond, luS.Cond())
+	}
+	if ch.Factorize(singular) {
+		t.Error("float32 Cholesky succeeded on a singular matrix")
+	}
+	if err := ch.Solve(&DenseMOf[float32]{}, NewDenseMatrixOf[float32](2, 1, nil)); !errors.Is(err, ErrNotPD) {
+		t.Errorf("expected ErrNotPD, got %v", err)
+	}
+
+	const m = 9
+	Atall := NewDenseMatrix(m, n, randomSlice(rng, m*n))
+	var btall DenseV
+	btall.MulVec(Atall, want)
+	qr.Factorize(float32Dense(Atall))
+	if err := qr.SolveLeastSquares(&x, float32Dense(&btall).ColView(0)); err != nil {
+		t.Fatal(err)
+	}
+	if !float32EqualTol(&x, want, 1e-3) {
+		t.Errorf("float32 QR solution %v, want %v", x.data, want.data)
+	}
+	var Q, R, QR DenseMOf[float32]
+	qr.QTo(&Q)
+	qr.RTo(&R)
+	QR.Mul(&Q, &R)
+	if !float32EqualTol(&QR, Atall, 1e-5) {
+		t.Errorf("float32 Q*R != A:\n%v\n%v", QR.data, Atall.data)
+	}
+}
+
+func TestGenericDecompositions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{{5, 3}, {3, 5}} {
+		m, n := dims[0], dims[1]
+		A := NewDenseMatrix(m, n, randomSlice(rng, m*n))
+		var svd SVDOf[float32]
+		if !svd.Factorize(float32Dense(A), SVDFull) {
+			t.Fatalf("%dx%d: float32 SVD did not converge", m, n)
+		}
+		var ref SVD
+		ref.Factorize(A, SVDNone)
+		s := svd.Values(nil)
+		for i, want := range ref.Values(nil) {
+			if !almostEqual(float64(s[i]), want, 1e-5) {
+				t.Errorf("%dx%d: float32 singular values %v, want %v", m, n, s, ref.Values(nil))
+				break
+			}
+		}
+		var U, V, US, USVT DenseMOf[float32]
+		svd.UTo(&U)
+		svd.VTo(&V)
+		Sigma := NewDenseMatrixOf[float32](m, n, nil)
+		for i, v := range s {
+			Sigma.Set(i, i, v)
+		}
+		US.Mul(&U, Sigma)
+		VT := NewDenseMatrixOf[float32](n, n, nil)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				VT.Set(i, j, V.At(j, i))
+			}
+		}
+		USVT.Mul(&US, VT)
+		if !float32EqualTol(&USVT, A, 1e-5) {
+			t.Errorf("%dx%d: float32 U*Σ*V^T != A", m, n)
+		}
+		var pinv DenseMOf[float32]
+		svd.PseudoInverse(&pinv, svd.Rank(0))
+		var refPinv DenseM
+		ref.Factorize(A, SVDThin)
+		ref.PseudoInverse(&refPinv, ref.Rank(0))
+		if !float32EqualTol(&pinv, &refPinv, 1e-4) {
+			t.Errorf("%dx%d: float32 pseudo inverse %v, want %v", m, n, pinv.data, refPinv.data)
+		}
+	}
+
+	// The float64 instantiation shares its implementation with SVD.
+	A := NewDenseMatrix(4, 3, randomSlice(rng, 12))
+	var svd64 SVDOf[float64]
+	svd64.Factorize(A, SVDThin)
+	var ref SVD
+	ref.Factorize(A, SVDThin)
+	var U64 DenseMOf[float64]
+	var Uref DenseM
+	svd64.UTo(&U64)
+	ref.UTo(&Uref)
+	if !matrixEqual(&U64, &Uref) || svd64.Cond() != ref.Cond() {
+		t.Error("SVDOf[float64] differs from SVD")
+	}
+
+	const n = 6
+	B := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	var S DenseM
+	S.Add(B, T(B))
+	var sym EigenSymOf[float32]
+	if !sym.Factorize(float32Dense(&S), true) {
+		t.Fatal("float32 EigenSym did not converge")
+	}
+	var symRef EigenSym
+	symRef.Factorize(&S, false)
+	values := sym.Values(nil)
+	for i, want := range symRef.Values(nil) {
+		if !almostEqual(float64(values[i]), want, 1e-5*Norm(&S, 2)) {
+			t.Errorf("float32 symmetric eigenvalues %v, want %v", values, symRef.Values(nil))
+			break
+		}
+	}
+	var V, AV DenseMOf[float32]
+	sym.VectorsTo(&V)
+	AV.Mul(float32Dense(&S), &V)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			if math.Abs(float64(AV.At(i, j)-values[j]*V.At(i, j))) > 1e-5*Norm(&S, 2) {
+				t.Fatalf("float32 A*v != λ*v for λ=%v", values[j])
+			}
+		}
+	}
+
+	G := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	var eig EigenOf[float32]
+	if !eig.Factorize(float32Dense(G), EigenBoth) {
+		t.Fatal("float32 Eigen did not converge")
+	}
+	var VR, VL DenseMOf[float32]
+	eig.VectorsTo(&VR)
+	eig.LeftVectorsTo(&VL)
+	cvalues := eig.Values(nil)
+	right := complexVectors(float64Dense(&VR), cvalues)
+	left := complexVectors(float64Dense(&VL), cvalues)
+	for j, lambda := range cvalues {
+		for i := 0; i < n; i++ {
+			var Av, wA complex128
+			for k := 0; k < n; k++ {
+				Av += complex(float64(float32(G.At(i, k))), 0) * right[j][k]
+				wA += cmplx.Conj(left[j][k]) * complex(float64(float32(G.At(k, i))), 0)
+			}
+			if cmplx.Abs(Av-lambda*right[j][i]) > 1e-4 {
+				t.Errorf("float32 A*v != λ*v for λ=%v", lambda)
+			}
+			if cmplx.Abs(wA-lambda*cmplx.Conj(left[j][i])) > 1e-4 {
+				t.Errorf("float32 w^H*A != λ*w^H for λ=%v", lambda)
+			}
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package lap
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrBreakdown is returned by an iterative solver when a division by zero
+// prevents the iteration from continuing.
+var ErrBreakdown = errors.New("iterative method breakdown")
+
+var (
+	_ LinearOperator = &Sparse{}
+	_ LinearOperator = &CSR{}
+	_ LinearOperator = &CSC{}
+)
+
+// LinearOperator is a linear map y = A*x. It allows iterative solvers to work
+// with matrices that are never formed explicitly or are stored in a sparse format.
+type LinearOperator interface {
+	// Dims returns the dimensions of the operator.
+	Dims() (r, c int)
+	// MulVecTo computes A*x and stores the result in dst.
+	MulVecTo(dst *DenseV, x Vector)
+}
+
+// Preconditioner approximates the inverse of a linear operator to accelerate
+// the convergence of iterative solvers.
+type Preconditioner interface {
+	// Apply computes an approximation of A^-1 * r and stores it in dst.
+	Apply(dst *DenseV, r Vector)
+}
+
+// IterSettings configures the iterative solvers. The zero value is valid.
+type IterSettings struct {
+	// Tolerance is the relative residual norm ||b-A*x||/||b|| below which
+	// the solution is considered converged. Defaults to 1e-8 if not positive.
+	Tolerance float64
+	// MaxIterations is the maximum number of iterations performed.
+	// Defaults to twice the dimension of the system if not positive.
+	MaxIterations int
+	// InitX is the initial guess of the solution. The zero vector is used if nil.
+	InitX Vector
+	// Preconditioner is applied to the residual each iteration. No
+	// preconditioning is performed if nil.
+	Preconditioner Preconditioner
+	// Restart is the number of iterations after which GMRES restarts.
+	// Defaults to 30 if not positive.
+	Restart int
+}
+
+// IterResult holds the outcome of an iterative solve.
+type IterResult struct {
+	// Iterations is the number of iterations performed.
+	Iterations int
+	// Residual is the final residual norm ||b-A*x||.
+	Residual float64
+	// Converged is true if the relative residual norm fell below the tolerance.
+	Converged bool
+	// History holds the residual norm of the initial guess followed by the
+	// residual norm after each iteration.
+	History []float64
+}
+
+// CG solves the symmetric positive definite system A*x = b with the preconditioned
+// conjugate gradient method and stores the solution in dst. If dst is not
+// initialized it is allocated automatically. The preconditioner, if any, must
+// also be symmetric positive definite.
+//
+// CG returns ErrNotPD if it detects that A or the preconditioner is not
+// positive definite. Failure to converge is not an error and is reported
+// in the result.
+func CG(dst *DenseV, A LinearOperator, b Vector, settings IterSettings) (IterResult, error) {
+	n := checkIterDims(dst, A, b)
+	settings = settings.defaults(n)
+	var (
+		x = NewDenseVector(n, nil)
+		r = NewDenseVector(n, nil)
+		z = NewDenseVector(n, nil)
+		p = NewDenseVector(n, nil)
+		q = NewDenseVector(n, nil)
+	)
+	result := settings.newResult()
+	tol := settings.Tolerance * Norm(b, 2)
+	initResidual(x, r, A, b, settings.InitX)
+	if result.record(nrm2(r.data), tol) {
+		dst.CopyVec(x)
+		return result, nil
+	}
+	applyPrecond(settings.Preconditioner, z, r)
+	p.CopyVec(z)
+	rz := dot(r.data, z.data)
+	for result.Iterations < settings.MaxIterations {
+		result.Iterations++
+		A.MulVecTo(q, p)
+		pq := dot(p.data, q.data)
+		if pq <= 0 || rz <= 0 {
+			dst.CopyVec(x)
+			return result, ErrNotPD
+		}
+		alpha := rz / pq
+		axpy(alpha, p.data, x.data)
+		axpy(-alpha, q.data, r.data)
+		if result.record(nrm2(r.data), tol) {
+			break
+		}
+		applyPrecond(settings.Preconditioner, z, r)
+		rzNew := dot(r.data, z.data)
+		beta := rzNew / rz
+		rz = rzNew
+		for i := range p.data {
+			p.data[i] = z.data[i] + beta*p.data[i]
+		}
+	}
+	dst.CopyVec(x)
+	return result, nil
+}
+
+// GMRES solves the general system A*x = b with the restarted generalized minimal
+// residual method GMRES(m) and stores the solution in dst. If dst is not initialized
+// it is allocated automatically. The restart length m is set by settings.Restart.
+// The preconditioner, if any, is applied from the right so the reported
+// residuals are those of the original system.
+//
+// The residuals recorded in the history within a restart cycle are the estimates
+// computed by the Arnoldi process, while the final residual is computed explicitly.
+// Failure to converge is not an error and is reported in the result.
+func GMRES(dst *DenseV, A LinearOperator, b Vector, settings IterSettings) (IterResult, error) {
+	n := checkIterDims(dst, A, b)
+	settings = settings.defaults(n)
+	m := settings.Restart
+	var (
+		x = NewDenseVector(n, nil)
+		r = NewDenseVector(n, nil)
+		w = NewDenseVector(n, nil)
+		z = NewDenseVector(n, nil)
+		V = make([]*DenseV, m+1)
+		// H is the (m+1)xm upper Hessenberg matrix of the Arnoldi process,
+		// reduced to upper triangular form by Givens rotations as it is built.
+		H      = NewDenseMatrix(m+1, m, nil)
+		cs, sn = make([]float64, m), make([]float64, m)
+		g, y   = make([]float64, m+1), make([]float64, m)
+	)
+	for i := range V {
+		V[i] = NewDenseVector(n, nil)
+	}
+	result := settings.newResult()
+	tol := settings.Tolerance * Norm(b, 2)
+	initResidual(x, r, A, b, settings.InitX)
+	beta := nrm2(r.data)
+	if result.record(beta, tol) {
+		dst.CopyVec(x)
+		return result, nil
+	}
+	h := H.rows()
+	for result.Iterations < settings.MaxIterations {
+		for i := range V[0].data {
+			V[0].data[i] = r.data[i] / beta
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = beta
+		var k int
+		for k < m && result.Iterations < settings.MaxIterations {
+			result.Iterations++
+			// Arnoldi step with modified Gram-Schmidt orthogonalization.
+			applyPrecond(settings.Preconditioner, z, V[k])
+			A.MulVecTo(w, z)
+			for i := 0; i <= k; i++ {
+				h[i][k] = dot(w.data, V[i].data)
+				axpy(-h[i][k], V[i].data, w.data)
+			}
+			hnext := nrm2(w.data)
+			h[k+1][k] = hnext
+			if hnext != 0 {
+				for i := range w.data {
+					V[k+1].data[i] = w.data[i] / hnext
+				}
+			}
+			// Apply previous rotations to the new column and compute a
+			// rotation that annihilates the subdiagonal element.
+			for i := 0; i < k; i++ {
+				hi, hi1 := h[i][k], h[i+1][k]
+				h[i][k] = cs[i]*hi + sn[i]*hi1
+				h[i+1][k] = -sn[i]*hi + cs[i]*hi1
+			}
+			rho := math.Hypot(h[k][k], h[k+1][k])
+			if rho == 0 {
+				dst.CopyVec(x)
+				return result, ErrBreakdown
+			}
+			cs[k], sn[k] = h[k][k]/rho, h[k+1][k]/rho
+			h[k][k] = rho
+			h[k+1][k] = 0
+			g[k+1] = -sn[k] * g[k]
+			g[k] = cs[k] * g[k]
+			k++
+			resid := math.Abs(g[k])
+			result.History = append(result.History, resid)
+			if resid <= tol || hnext == 0 {
+				break
+			}
+		}
+		// Solve the triangular least squares system and update x.
+		for i := k - 1; i >= 0; i-- {
+			sum := g[i]
+			for j := i + 1; j < k; j++ {
+				sum -= h[i][j] * y[j]
+			}
+			y[i] = sum / h[i][i]
+		}
+		for i := range w.data {
+			w.data[i] = 0
+		}
+		for j := 0; j < k; j++ {
+			axpy(y[j], V[j].data, w.data)
+		}
+		applyPrecond(settings.Preconditioner, z, w)
+		axpy(1, z.data, x.data)
+		A.MulVecTo(r, x)
+		for i := range r.data {
+			r.data[i] = b.AtVec(i) - r.data[i]
+		}
+		beta = nrm2(r.data)
+		result.Residual = beta
+		if beta <= tol {
+			result.Converged = true
+			break
+		}
+	}
+	dst.CopyVec(x)
+	return result, nil
+}
+
+// BiCGSTAB solves the general system A*x = b with the biconjugate gradient
+// stabilized method and stores the solution in dst. If dst is not initialized
+// it is allocated automatically. The preconditioner, if any, is applied from the right.
+//
+// BiCGSTAB returns ErrBreakdown if the iteration cannot continue.
+// Failure to converge is not an error and is reported in the result.
+func BiCGSTAB(dst *DenseV, A LinearOperator, b Vector, settings IterSettings) (IterResult, error) {
+	n := checkIterDims(dst, A, b)
+	settings = settings.defaults(n)
+	var (
+		x    = NewDenseVector(n, nil)
+		r    = NewDenseVector(n, nil)
+		rhat = NewDenseVector(n, nil)
+		p    = NewDenseVector(n, nil)
+		phat = NewDenseVector(n, nil)
+		v    = NewDenseVector(n, nil)
+		s    = NewDenseVector(n, nil)
+		shat = NewDenseVector(n, nil)
+		t    = NewDenseVector(n, nil)
+	)
+	result := settings.newResult()
+	tol := settings.Tolerance * Norm(b, 2)
+	initResidual(x, r, A, b, settings.InitX)
+	if result.record(nrm2(r.data), tol) {
+		dst.CopyVec(x)
+		return result, nil
+	}
+	copy(rhat.data, r.data)
+	rho, alpha, omega := 1.0, 1.0, 1.0
+	for result.Iterations < settings.MaxIterations {
+		result.Iterations++
+		rhoNew := dot(rhat.data, r.data)
+		if rhoNew == 0 || omega == 0 {
+			dst.CopyVec(x)
+			return result, ErrBreakdown
+		}
+		if result.Iterations == 1 {
+			copy(p.data, r.data)
+		} else {
+			beta := (rhoNew / rho) * (alpha / omega)
+			for i := range p.data {
+				p.data[i] = r.data[i] + beta*(p.data[i]-omega*v.data[i])
+			}
+		}
+		rho = rhoNew
+		applyPrecond(settings.Preconditioner, phat, p)
+		A.MulVecTo(v, phat)
+		rv := dot(rhat.data, v.data)
+		if rv == 0 {
+			dst.CopyVec(x)
+			return result, ErrBreakdown
+		}
+		alpha = rho / rv
+		for i := range s.data {
+			s.data[i] = r.data[i] - alpha*v.data[i]
+		}
+		axpy(alpha, phat.data, x.data)
+		snorm := nrm2(s.data)
+		if snorm <= tol {
+			copy(r.data, s.data)
+			result.record(snorm, tol)
+			break
+		}
+		applyPrecond(settings.Preconditioner, shat, s)
+		A.MulVecTo(t, shat)
+		tt := dot(t.data, t.data)
+		if tt == 0 {
+			dst.CopyVec(x)
+			return result, ErrBreakdown
+		}
+		omega = dot(t.data, s.data) / tt
+		axpy(omega, shat.data, x.data)
+		for i := range r.data {
+			r.data[i] = s.data[i] - omega*t.data[i]
+		}
+		if result.record(nrm2(r.data), tol) {
+			break
+		}
+	}
+	dst.CopyVec(x)
+	return result, nil
+}
+
+func (s IterSettings) defaults(n int) IterSettings {
+	if s.Tolerance <= 0 {
+		s.Tolerance = 1e-8
+	}
+	if s.MaxIterations <= 0 {
+		s.MaxIterations = 2 * n
+	}
+	if s.Restart <= 0 {
+		s.Restart = 30
+	}
+	s.Restart = min(s.Restart, max(n, 1))
+	if s.InitX != nil && s.InitX.Len() != n {
+		panic(ErrDim)
+	}
+	return s
+}
+
+// newResult returns a result with enough history capacity for the iteration
+// so that recording residuals does not allocate.
+func (s IterSettings) newResult() IterResult {
+	return IterResult{History: make([]float64, 0, s.MaxIterations+1)}
+}
+
+// record stores the residual norm in the result and reports whether it
+// is below the tolerance.
+func (r *IterResult) record(resid, tol float64) (converged bool) {
+	r.Residual = resid
+	r.History = append(r.History, resid)
+	r.Converged = resid <= tol
+	return r.Converged
+}
+
+// checkIterDims checks that A is square and matches b and dst, allocating dst
+// if it is not initialized. It returns the dimension of the system.
+func checkIterDims(dst *DenseV, A LinearOperator, b Vector) int {
+	n, c := A.Dims()
+	if n != c || b.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	return n
+}
+
+// initResidual sets x to the initial guess and r to b - A*x.
+func initResidual(x, r *DenseV, A LinearOperator, b, initX Vector) {
+	if initX == nil {
+		r.CopyVec(b)
+		return
+	}
+	x.CopyVec(initX)
+	A.MulVecTo(r, x)
+	for i := range r.data {
+		r.data[i] = b.AtVec(i) - r.data[i]
+	}
+}
+
+// applyPrecond stores M^-1 * r in dst, or r if M is nil.
+func applyPrecond(M Preconditioner, dst, r *DenseV) {
+	if M == nil {
+		copy(dst.data, r.data)
+		return
+	}
+	M.Apply(dst, r)
+}
+
+func dot(x, y []float64) (sum float64) {
+	for i, v := range x {
+		sum += v * y[i]
+	}
+	return sum
+}
+
+func nrm2(x []float64) float64 {
+	return math.Sqrt(dot(x, x))
+}
+
+// axpy computes y += alpha*x.
+func axpy(alpha float64, x, y []float64) {
+	for i, v := range x {
+		y[i] += alpha * v
+	}
+}
@@ -0,0 +1,190 @@
+package lap
+
+import (
+	"errors"
+	"testing"
+)
+
+// laplacian1D returns the nxn tridiagonal matrix with 2 on the diagonal
+// and -1 on the off diagonals.
+func laplacian1D(n int) *Sparse {
+	acc := NewSparseAccum(3*n - 2)
+	var k int
+	for i := 0; i < n; i++ {
+		acc.Set(k, i, i, 2)
+		k++
+		if i > 0 {
+			acc.Set(k, i, i-1, -1)
+			acc.Set(k+1, i-1, i, -1)
+			k += 2
+		}
+	}
+	s := NewSparse(n, n)
+	s.Accumulate(acc)
+	return s
+}
+
+// diagPrecond is a minimal preconditioner for testing.
+type diagPrecond []float64
+
+func (d diagPrecond) Apply(dst *DenseV, r Vector) {
+	for i, v := range d {
+		dst.SetVec(i, r.AtVec(i)/v)
+	}
+}
+
+func TestCG(t *testing.T) {
+	const n = 50
+	A := laplacian1D(n)
+	want := NewDenseVector(n, nil)
+	want.DoSetVec(func(i int, _ float64) float64 { return float64(i%7) - 3 })
+	var b DenseV
+	A.MulVecTo(&b, want)
+
+	diag := make(diagPrecond, n)
+	for i := range diag {
+		diag[i] = 2
+	}
+	for _, op := range []LinearOperator{A, NewCSR(A), NewCSC(A)} {
+		for _, M := range []Preconditioner{nil, diag} {
+			var x DenseV
+			result, err := CG(&x, op, &b, IterSettings{Tolerance: 1e-12, Preconditioner: M})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Converged {
+				t.Errorf("%T: did not converge after %d iterations, residual %g", op, result.Iterations, result.Residual)
+			}
+			if result.Iterations > n {
+				t.Errorf("%T: too many iterations %d", op, result.Iterations)
+			}
+			if !vectorEqualTol(&x, want, 1e-9) {
+				t.Errorf("%T: bad solution", op)
+			}
+		}
+	}
+
+	// Starting at the solution converges immediately.
+	var x DenseV
+	result, err := CG(&x, A, &b, IterSettings{InitX: want})
+	if err != nil || !result.Converged || result.Iterations != 0 {
+		t.Errorf("expected immediate convergence, got %+v, %v", result, err)
+	}
+
+	// Limiting iterations reports non-convergence.
+	result, err = CG(&x, A, &b, IterSettings{MaxIterations: 3})
+	if err != nil || result.Converged || result.Iterations != 3 {
+		t.Errorf("expected 3 iterations without convergence, got %+v, %v", result, err)
+	}
+
+	indefinite := NewSparse(2, 2)
+	indefinite.Set(0, 0, 1)
+	indefinite.Set(1, 1, -1)
+	var x2 DenseV
+	_, err = CG(&x2, indefinite, NewDenseVector(2, []float64{1, 1}), IterSettings{})
+	if !errors.Is(err, ErrNotPD) {
+		t.Errorf("expected ErrNotPD, got %v", err)
+	}
+}
+
+// convectionDiffusion1D returns the nonsymmetric nxn tridiagonal matrix of a
+// centered difference discretization of -u_xx + c*u_x.
+func convectionDiffusion1D(n int, c float64) *Sparse {
+	s := NewSparse(n, n)
+	for i := 0; i < n; i++ {
+		s.Set(i, i, 2)
+		if i > 0 {
+			s.Set(i, i-1, -1-c)
+		}
+		if i < n-1 {
+			s.Set(i, i+1, -1+c)
+		}
+	}
+	return s
+}
+
+func TestNonsymmetricSolvers(t *testing.T) {
+	const n = 40
+	A := convectionDiffusion1D(n, 0.4)
+	want := NewDenseVector(n, nil)
+	want.DoSetVec(func(i int, _ float64) float64 { return float64(i%5) - 2 })
+	var b DenseV
+	A.MulVecTo(&b, want)
+
+	diag := make(diagPrecond, n)
+	for i := range diag {
+		diag[i] = 2
+	}
+	solvers := map[string]func(*DenseV, LinearOperator, Vector, IterSettings) (IterResult, error){
+		"GMRES":    GMRES,
+		"BiCGSTAB": BiCGSTAB,
+	}
+	for name, solve := range solvers {
+		for _, op := range []LinearOperator{A, NewCSR(A)} {
+			for _, M := range []Preconditioner{nil, diag} {
+				for _, restart := range []int{0, 10} {
+					var x DenseV
+					settings := IterSettings{Tolerance: 1e-12, MaxIterations: 10 * n, Preconditioner: M, Restart: restart}
+					result, err := solve(&x, op, &b, settings)
+					if err != nil {
+						t.Fatalf("%s %T: %v", name, op, err)
+					}
+					if !result.Converged {
+						t.Errorf("%s %T: did not converge after %d iterations, residual %g", name, op, result.Iterations, result.Residual)
+					}
+					if !vectorEqualTol(&x, want, 1e-8) {
+						t.Errorf("%s %T: bad solution", name, op)
+					}
+					if len(result.History) == 0 || result.History[0] != Norm(&b, 2) {
+						t.Errorf("%s %T: history does not start with the initial residual", name, op)
+					}
+				}
+			}
+		}
+
+		var x DenseV
+		result, err := solve(&x, A, &b, IterSettings{InitX: want})
+		if err != nil || !result.Converged || result.Iterations != 0 {
+			t.Errorf("%s: expected immediate convergence, got %+v, %v", name, result, err)
+		}
+		result, err = solve(&x, A, &b, IterSettings{MaxIterations: 3})
+		if err != nil || result.Converged || result.Iterations != 3 {
+			t.Errorf("%s: expected 3 iterations without convergence, got %+v, %v", name, result, err)
+		}
+	}
+
+	// A full GMRES cycle on an nxn system converges in at most n iterations
+	// and the residual never increases.
+	var x DenseV
+	result, err := GMRES(&x, A, &b, IterSettings{Tolerance: 1e-10, Restart: n})
+	if err != nil || !result.Converged || result.Iterations > n {
+		t.Fatalf("GMRES without restart: %+v, %v", result, err)
+	}
+	for i := 1; i < len(result.History); i++ {
+		if result.History[i] > result.History[i-1]*(1+1e-12) {
+			t.Errorf("GMRES residual increased at iteration %d", i)
+		}
+	}
+}
+
+func TestIterativeNoAlloc(t *testing.T) {
+	const n = 30
+	A := NewCSR(convectionDiffusion1D(n, 0.2))
+	b := NewDenseVector(n, nil)
+	b.DoSetVec(func(i int, _ float64) float64 { return 1 })
+	settings := IterSettings{Tolerance: 1e-20, MaxIterations: 1}
+	var x DenseV
+	allocs := map[string]float64{}
+	for _, it := range []int{10, 100} {
+		settings.MaxIterations = it
+		for name, solve := range map[string]func(*DenseV, LinearOperator, Vector, IterSettings) (IterResult, error){
+			"CG": CG, "GMRES": GMRES, "BiCGSTAB": BiCGSTAB,
+		} {
+			a := testing.AllocsPerRun(5, func() { solve(&x, A, b, settings) })
+			if prev, ok := allocs[name]; ok && a != prev {
+				t.Errorf("%s allocations depend on iteration count: %v != %v", name, prev, a)
+			}
+			allocs[name] = a
+		}
+	}
+}
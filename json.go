@@ -0,0 +1,222 @@
+package lap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	_ json.Marshaler   = &DenseM{}
+	_ json.Unmarshaler = &DenseM{}
+	_ json.Marshaler   = &DenseV{}
+	_ json.Unmarshaler = &DenseV{}
+	_ json.Marshaler   = &Sparse{}
+	_ json.Unmarshaler = &Sparse{}
+)
+
+type denseMJSON struct {
+	Rows int        `json:"rows"`
+	Cols int        `json:"cols"`
+	Data jsonFloats `json:"data"`
+}
+
+type denseVJSON struct {
+	Len  int        `json:"len"`
+	Data jsonFloats `json:"data"`
+}
+
+type sparseJSON struct {
+	Rows int        `json:"rows"`
+	Cols int        `json:"cols"`
+	I    []int      `json:"i"`
+	J    []int      `json:"j"`
+	Data jsonFloats `json:"data"`
+}
+
+// MarshalJSON encodes the receiver as a JSON object of the form
+//
+//	{"rows":r,"cols":c,"data":[...]}
+//
+// where data holds the r*c elements in row-major order. Values are encoded
+// as JSON numbers, except NaN, +Inf and -Inf which JSON can not represent and
+// are encoded as the strings "NaN", "+Inf" and "-Inf".
+func (m *DenseM) MarshalJSON() ([]byte, error) {
+	data := make([]float64, 0, m.r*m.c)
+	for i := 0; i < m.r; i++ {
+		data = append(data, m.data[i*m.stride:i*m.stride+m.c]...)
+	}
+	return json.Marshal(denseMJSON{Rows: m.r, Cols: m.c, Data: data})
+}
+
+// UnmarshalJSON decodes the JSON form produced by MarshalJSON into the receiver.
+// If the receiver is empty it is allocated, otherwise the encoded dimensions
+// must match those of the receiver and ErrDim is returned if they do not.
+func (m *DenseM) UnmarshalJSON(b []byte) error {
+	var v denseMJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	// Compare by division so that a large rows*cols can not overflow.
+	n := len(v.Data)
+	if v.Rows < 0 || v.Cols < 0 || (v.Cols == 0 && n != 0) || (v.Cols != 0 && (n%v.Cols != 0 || n/v.Cols != v.Rows)) {
+		return fmt.Errorf("%d elements for %dx%d matrix: %w", len(v.Data), v.Rows, v.Cols, ErrDim)
+	}
+	if m.data == nil {
+		*m = *NewDenseMatrix(v.Rows, v.Cols, nil)
+	}
+	if m.r != v.Rows || m.c != v.Cols {
+		return ErrDim
+	}
+	for i := 0; i < m.r; i++ {
+		copy(m.data[i*m.stride:i*m.stride+m.c], v.Data[i*m.c:])
+	}
+	return nil
+}
+
+// MarshalJSON encodes the receiver as a JSON object of the form
+//
+//	{"len":n,"data":[...]}
+//
+// Values are encoded as described in DenseM.MarshalJSON.
+func (v *DenseV) MarshalJSON() ([]byte, error) {
+	l := v.Len()
+	data := make([]float64, l)
+	for i := range data {
+		data[i] = v.AtVec(i)
+	}
+	return json.Marshal(denseVJSON{Len: l, Data: data})
+}
+
+// UnmarshalJSON decodes the JSON form produced by MarshalJSON into the receiver.
+// If the receiver is empty it is allocated, otherwise the encoded length
+// must match that of the receiver and ErrDim is returned if it does not.
+func (v *DenseV) UnmarshalJSON(b []byte) error {
+	var enc denseVJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if len(enc.Data) != enc.Len {
+		return fmt.Errorf("%d elements for vector of length %d: %w", len(enc.Data), enc.Len, ErrDim)
+	}
+	if v.data == nil {
+		*v = *NewDenseVector(enc.Len, nil)
+	}
+	if v.Len() != enc.Len {
+		return ErrDim
+	}
+	for i, x := range enc.Data {
+		v.SetVec(i, x)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the receiver in triplet form as a JSON object
+//
+//	{"rows":r,"cols":c,"i":[...],"j":[...],"data":[...]}
+//
+// where the kth non-zero entry is at row i[k], column j[k] and has value data[k].
+// Entries are sorted by row and then column. Values are encoded as described
+// in DenseM.MarshalJSON.
+func (s *Sparse) MarshalJSON() ([]byte, error) {
+	enc := sparseJSON{
+		Rows: s.r,
+		Cols: s.c,
+		I:    make([]int, 0, len(s.m)),
+		J:    make([]int, 0, len(s.m)),
+		Data: make(jsonFloats, 0, len(s.m)),
+	}
+	NewCSR(s).DoNonZero(func(i, j int, v float64) {
+		enc.I = append(enc.I, i)
+		enc.J = append(enc.J, j)
+		enc.Data = append(enc.Data, v)
+	})
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes the JSON form produced by MarshalJSON into the receiver,
+// replacing its entries. Duplicate entries are summed. If the receiver has no
+// dimensions it is resized to the encoded dimensions, otherwise they must
+// match and ErrDim is returned if they do not.
+func (s *Sparse) UnmarshalJSON(b []byte) error {
+	var enc sparseJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if enc.Rows < 0 || enc.Cols < 0 || len(enc.I) != len(enc.Data) || len(enc.J) != len(enc.Data) {
+		return fmt.Errorf("mismatched triplet lengths: %w", ErrDim)
+	}
+	for k := range enc.I {
+		if enc.I[k] < 0 || enc.I[k] >= enc.Rows || enc.J[k] < 0 || enc.J[k] >= enc.Cols {
+			return fmt.Errorf("entry (%d, %d) out of bounds of %dx%d matrix: %w", enc.I[k], enc.J[k], enc.Rows, enc.Cols, ErrDim)
+		}
+	}
+	if s.r == 0 && s.c == 0 {
+		*s = *NewSparse(enc.Rows, enc.Cols)
+	}
+	if s.r != enc.Rows || s.c != enc.Cols {
+		return ErrDim
+	}
+	s.m = make(map[[2]int]float64, len(enc.Data))
+	for k, v := range enc.Data {
+		s.Set(enc.I[k], enc.J[k], s.At(enc.I[k], enc.J[k])+v)
+	}
+	return nil
+}
+
+// jsonFloats is a slice of floats that encodes non-finite values as the
+// strings "NaN", "+Inf" and "-Inf".
+type jsonFloats []float64
+
+func (f jsonFloats) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 2+len(f)*8)
+	b = append(b, '[')
+	for i, v := range f {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		switch {
+		case math.IsNaN(v):
+			b = append(b, `"NaN"`...)
+		case math.IsInf(v, 1):
+			b = append(b, `"+Inf"`...)
+		case math.IsInf(v, -1):
+			b = append(b, `"-Inf"`...)
+		default:
+			b = strconv.AppendFloat(b, v, 'g', -1, 64)
+		}
+	}
+	return append(b, ']'), nil
+}
+
+func (f *jsonFloats) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*f = make(jsonFloats, len(raw))
+	for i, r := range raw {
+		if len(r) > 0 && r[0] == '"' {
+			var s string
+			if err := json.Unmarshal(r, &s); err != nil {
+				return err
+			}
+			switch s {
+			case "NaN":
+				(*f)[i] = math.NaN()
+			case "+Inf":
+				(*f)[i] = math.Inf(1)
+			case "-Inf":
+				(*f)[i] = math.Inf(-1)
+			default:
+				return fmt.Errorf("invalid number %q at index %d", s, i)
+			}
+			continue
+		}
+		if err := json.Unmarshal(r, &(*f)[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
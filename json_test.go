@@ -0,0 +1,88 @@
+package lap
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	big := NewDenseMatrix(3, 4, []float64{
+		1, 2, 3, 4,
+		5, math.NaN(), math.Inf(1), 8,
+		9, 10, math.Inf(-1), 0.1,
+	})
+	view := big.Slice(1, 3, 1, 4)
+	b, err := json.Marshal(view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"rows":2,"cols":3,"data":["NaN","+Inf",8,10,"-Inf",0.1]}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	var m DenseM
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if r, c := m.Dims(); r != 2 || c != 3 || !math.IsNaN(m.At(0, 0)) || !math.IsInf(m.At(1, 1), -1) || m.At(1, 2) != 0.1 {
+		t.Errorf("round trip mismatch: %v", m.data)
+	}
+	if err := json.Unmarshal(b, NewDenseMatrix(3, 2, nil)); err != ErrDim {
+		t.Errorf("expected ErrDim, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"rows":2,"cols":2,"data":[1,2,3]}`), &DenseM{}); err == nil {
+		t.Error("expected error for wrong element count")
+	}
+	for _, bad := range []string{
+		`{"rows":4294967296,"cols":4294967296,"data":[]}`,
+		`{"rows":-1,"cols":-2,"data":[1,2]}`,
+		`{"rows":3,"cols":0,"data":[1]}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &DenseM{}); !errors.Is(err, ErrDim) {
+			t.Errorf("%s: expected ErrDim, got %v", bad, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"rows":1,"cols":1,"data":["Inf"]}`), &DenseM{}); err == nil {
+		t.Error("expected error for invalid number string")
+	}
+
+	v := NewDenseVector(3, []float64{1.5, math.Inf(1), -2})
+	b, err = json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"len":3,"data":[1.5,"+Inf",-2]}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	var gotv DenseV
+	if err := json.Unmarshal(b, &gotv); err != nil {
+		t.Fatal(err)
+	}
+	if !vectorEqual(&gotv, v) {
+		t.Errorf("vector round trip mismatch: %v", gotv.data)
+	}
+
+	s := NewSparse(3, 3)
+	s.Set(2, 0, 4)
+	s.Set(0, 2, -1)
+	s.Set(0, 1, 1e-10)
+	b, err = json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"rows":3,"cols":3,"i":[0,0,2],"j":[1,2,0],"data":[1e-10,-1,4]}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	var gots Sparse
+	if err := json.Unmarshal(b, &gots); err != nil {
+		t.Fatal(err)
+	}
+	if !matrixEqual(&gots, s) {
+		t.Error("sparse round trip mismatch")
+	}
+	if err := json.Unmarshal([]byte(`{"rows":2,"cols":2,"i":[2],"j":[0],"data":[1]}`), &Sparse{}); err == nil {
+		t.Error("expected error for out of bounds entry")
+	}
+}
@@ -11,6 +11,10 @@ import (
 	"math"
 )
 
+// epsilon is the machine epsilon of float64, the difference between 1 and the
+// next representable float64.
+const epsilon = 0x1p-52
+
 // Dot returns the sum of the element-wise product of a and b.
 //
 // Dot panics with ErrShape if the vector sizes are unequal.
@@ -66,16 +70,22 @@ func Min(m Matrix) float64 {
 //	2 - The Frobenius norm, the square root of the sum of the squares of the elements
 //	Inf - The maximum absolute row sum
 func Norm(A Matrix, norm float64) float64 {
+	return NormOf[float64](A, norm)
+}
+
+// NormOf returns the specified norm of the matrix A with elements of type T.
+// Valid norms are those accepted by Norm.
+func NormOf[T Float](A MatrixOf[T], norm float64) T {
 	r, c := A.Dims()
 	switch norm {
 	default:
 		panic("Bad norm order, accept 1, 2, +Inf")
 	case 1:
-		var max float64
+		var max T
 		for j := 0; j < c; j++ {
-			var sum float64
+			var sum T
 			for i := 0; i < r; i++ {
-				sum += math.Abs(A.At(i, j))
+				sum += absOf(A.At(i, j))
 			}
 			if sum > max {
 				max = sum
@@ -83,20 +93,20 @@ func Norm(A Matrix, norm float64) float64 {
 		}
 		return max
 	case 2:
-		var sum float64
+		var sum T
 		for i := 0; i < r; i++ {
 			for j := 0; j < c; j++ {
 				v := A.At(i, j)
 				sum += v * v
 			}
 		}
-		return math.Sqrt(sum)
+		return sqrtOf(sum)
 	case math.Inf(1):
-		var max float64
+		var max T
 		for i := 0; i < r; i++ {
-			var sum float64
+			var sum T
 			for j := 0; j < c; j++ {
-				sum += math.Abs(A.At(i, j))
+				sum += absOf(A.At(i, j))
 			}
 			if sum > max {
 				max = sum
@@ -134,6 +144,69 @@ func Argmax(A Matrix) (imax, jmax int) {
 	return imax, jmax
 }
 
+// Det returns the determinant of the square matrix A, computed via LU factorization.
+// Det panics with ErrDim if A is not square.
+func Det(A Matrix) float64 {
+	if d, ok := A.(*DenseM); ok && d.r == d.c && d.r <= 3 {
+		// Closed form for small matrices.
+		a := d.data
+		s := d.stride
+		switch d.r {
+		case 0:
+			return 1
+		case 1:
+			return a[0]
+		case 2:
+			return a[0]*a[s+1] - a[1]*a[s]
+		case 3:
+			return a[0]*(a[s+1]*a[2*s+2]-a[s+2]*a[2*s+1]) -
+				a[1]*(a[s]*a[2*s+2]-a[s+2]*a[2*s]) +
+				a[2]*(a[s]*a[2*s+1]-a[s+1]*a[2*s])
+		}
+	}
+	var lu LU
+	lu.Factorize(A)
+	return lu.Det()
+}
+
+// LogDet returns the log of the absolute value of the determinant of the square
+// matrix A and its sign, computed via LU factorization. The determinant is equal
+// to sign*exp(log). LogDet is more robust than Det to overflow and underflow for
+// large matrices. LogDet panics with ErrDim if A is not square.
+func LogDet(A Matrix) (log float64, sign float64) {
+	var lu LU
+	lu.Factorize(A)
+	return lu.LogDet()
+}
+
+// Trace returns the sum of the diagonal elements of the square matrix A.
+// Trace panics with ErrDim if A is not square.
+func Trace(A Matrix) (trace float64) {
+	r, c := A.Dims()
+	if r != c {
+		panic(ErrDim)
+	}
+	if d, ok := A.(*DenseM); ok {
+		for i := 0; i < r; i++ {
+			trace += d.data[i*d.stride+i]
+		}
+		return trace
+	}
+	for i := 0; i < r; i++ {
+		trace += A.At(i, i)
+	}
+	return trace
+}
+
+// Rank returns the numerical rank of A, the number of singular values of A
+// greater than tol times the largest singular value. If tol is not positive
+// a default tolerance of max(m,n) times the machine epsilon is used.
+func Rank(A Matrix, tol float64) int {
+	var svd SVD
+	svd.Factorize(A, SVDNone)
+	return svd.Rank(tol)
+}
+
 func irange(dst []int, start, stride int) {
 	for i := 0; i < len(dst); i++ {
 		dst[i] = start + i*stride
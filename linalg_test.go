@@ -76,3 +76,42 @@ func vectorEqualTol(a, b Vector, tol float64) bool {
 func vectorEqual(a, b Vector) bool {
 	return vectorEqualTol(a, b, 0)
 }
+
+func TestDetTraceRank(t *testing.T) {
+	var sparse = NewSparse(3, 3)
+	var dense4 = NewDenseMatrix(4, 4, nil)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			sparse.Set(i, j, magic3.At(i, j))
+		}
+	}
+	dense4.Copy(Eye(4))
+	dense4.Slice(0, 3, 0, 3).Copy(magic3)
+	for _, A := range []Matrix{magic3, T(magic3), sparse, Slice(magic3, []int{0, 1, 2}, nil), dense4} {
+		if det := Det(A); !almostEqual(det, -360, 1e-10) {
+			t.Errorf("%T: expected determinant -360, got %g", A, det)
+		}
+		logdet, sign := LogDet(A)
+		if sign != -1 || !almostEqual(logdet, math.Log(360), 1e-12) {
+			t.Errorf("%T: bad log determinant %g with sign %g", A, logdet, sign)
+		}
+		r, _ := A.Dims()
+		if tr := Trace(A); tr != 15+float64(r-3) {
+			t.Errorf("%T: bad trace %g", A, tr)
+		}
+		if rank := Rank(A, 0); rank != r {
+			t.Errorf("%T: expected rank %d, got %d", A, r, rank)
+		}
+	}
+	rankDeficient := NewDenseMatrix(3, 4, []float64{
+		1, 2, 3, 4,
+		2, 4, 6, 8,
+		0, 1, 0, 1,
+	})
+	if rank := Rank(rankDeficient, 0); rank != 2 {
+		t.Errorf("expected rank 2, got %d", rank)
+	}
+	if det := Det(NewDenseMatrix(2, 2, []float64{1, 2, 3, 4})); det != -2 {
+		t.Errorf("expected determinant -2, got %g", det)
+	}
+}
@@ -0,0 +1,561 @@
+package lap
+
+import "math"
+
+// LU is a type for creating and using the LU factorization of a square matrix.
+// The factorization is computed with partial (row) pivoting such that
+//
+//	P * A = L * U
+//
+// where P is a permutation matrix, L is unit lower triangular and U is upper triangular.
+// Once computed the factorization can be reused to solve for many right hand sides.
+type LU struct {
+	// lu stores L below the diagonal (unit diagonal implied) and U
+	// on and above the diagonal.
+	lu DenseM
+	// pivot[k] is the row swapped with row k during step k of the elimination.
+	pivot []int
+	swaps int
+	// anorm is the 1-norm of the factorized matrix, used for condition estimation.
+	anorm float64
+}
+
+// Factorize computes the LU factorization of the square matrix A and stores
+// the result in the receiver. Factorize panics with ErrDim if A is not square.
+// Factorization of a singular matrix succeeds, but subsequent solves will fail
+// with ErrSingular.
+func (lu *LU) Factorize(A Matrix) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	if lu.lu.r != n || lu.lu.c != n {
+		lu.lu = *NewDenseMatrix(n, n, nil)
+	}
+	lu.lu.Copy(A)
+	if cap(lu.pivot) < n {
+		lu.pivot = make([]int, n)
+	}
+	lu.pivot = lu.pivot[:n]
+	lu.anorm = Norm(A, 1)
+	lu.swaps = luFactorize(lu.lu.data, lu.lu.stride, n, lu.pivot)
+}
+
+// isSingular reports whether U has an exactly zero diagonal element.
+func (lu *LU) isSingular() bool {
+	return hasZeroDiag(lu.lu.data, lu.lu.stride, lu.lu.r)
+}
+
+// Det returns the determinant of the factorized matrix.
+func (lu *LU) Det() float64 {
+	det := 1.0
+	if lu.swaps%2 == 1 {
+		det = -1
+	}
+	for i := 0; i < lu.lu.r; i++ {
+		det *= lu.lu.data[i*lu.lu.stride+i]
+	}
+	return det
+}
+
+// LogDet returns the log of the absolute value of the determinant of the
+// factorized matrix and its sign. The determinant is equal to sign*exp(log).
+// LogDet is more robust than Det to overflow and underflow for large matrices.
+func (lu *LU) LogDet() (log float64, sign float64) {
+	n := lu.lu.r
+	sign = 1
+	if lu.swaps%2 == 1 {
+		sign = -1
+	}
+	for i := 0; i < n; i++ {
+		v := lu.lu.data[i*lu.lu.stride+i]
+		if v < 0 {
+			sign = -sign
+		}
+		log += math.Log(math.Abs(v))
+	}
+	return log, sign
+}
+
+// Pivot returns the row permutation of the factorization such that row i of P*A
+// is row perm[i] of A. If dst is nil a new slice is allocated, otherwise dst must
+// have length equal to the dimension of the factorized matrix.
+func (lu *LU) Pivot(dst []int) []int {
+	n := lu.lu.r
+	if dst == nil {
+		dst = make([]int, n)
+	}
+	if len(dst) != n {
+		panic(ErrDim)
+	}
+	irange(dst, 0, 1)
+	for k, p := range lu.pivot {
+		dst[k], dst[p] = dst[p], dst[k]
+	}
+	return dst
+}
+
+// LTo stores the unit lower triangular factor L in dst. If dst is not
+// initialized it is allocated automatically.
+func (lu *LU) LTo(dst *DenseM) {
+	n := lu.lu.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.DoSet(func(i, j int, _ float64) float64 {
+		switch {
+		case i == j:
+			return 1
+		case i > j:
+			return lu.lu.data[i*lu.lu.stride+j]
+		}
+		return 0
+	})
+}
+
+// UTo stores the upper triangular factor U in dst. If dst is not
+// initialized it is allocated automatically.
+func (lu *LU) UTo(dst *DenseM) {
+	n := lu.lu.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	dst.DoSet(func(i, j int, _ float64) float64 {
+		if i <= j {
+			return lu.lu.data[i*lu.lu.stride+j]
+		}
+		return 0
+	})
+}
+
+// Cond returns an estimate of the 1-norm condition number of the factorized matrix.
+// The returned value is +Inf if the matrix is singular.
+func (lu *LU) Cond() float64 {
+	rcond := lu.rcond()
+	if rcond == 0 {
+		return math.Inf(1)
+	}
+	return 1 / rcond
+}
+
+// rcond estimates the reciprocal of the 1-norm condition number using
+// Hager's method, which requires only a handful of solves.
+func (lu *LU) rcond() float64 {
+	return luRcond(lu.lu.data, lu.lu.stride, lu.lu.r, lu.pivot, lu.anorm)
+}
+
+// invNorm1 estimates the 1-norm of the inverse of a nonsingular nxn matrix A
+// with Hager's method. solve must overwrite x with the solution of A*x = x,
+// or A^T*x = x if trans is true.
+func invNorm1[T Float](n int, solve func(x []T, trans bool)) float64 {
+	x := make([]T, n)
+	y := make([]T, n)
+	z := make([]T, n)
+	for i := range x {
+		x[i] = 1 / T(n)
+	}
+	var ainvnorm float64
+	for iter := 0; iter < 5; iter++ {
+		copy(y, x)
+		solve(y, false)
+		est := 0.0
+		for _, v := range y {
+			est += math.Abs(float64(v))
+		}
+		if iter > 0 && est <= ainvnorm {
+			break
+		}
+		ainvnorm = est
+		for i, v := range y {
+			if v >= 0 {
+				z[i] = 1
+			} else {
+				z[i] = -1
+			}
+		}
+		solve(z, true)
+		jmax := 0
+		zmax := math.Abs(float64(z[0]))
+		ztx := float64(z[0] * x[0])
+		for i := 1; i < n; i++ {
+			if v := math.Abs(float64(z[i])); v > zmax {
+				jmax, zmax = i, v
+			}
+			ztx += float64(z[i] * x[i])
+		}
+		if zmax <= ztx {
+			break
+		}
+		for i := range x {
+			x[i] = 0
+		}
+		x[jmax] = 1
+	}
+	return ainvnorm
+}
+
+// Solve solves the system A*X = B, or A^T*X = B if trans is true, using the
+// factorization of A and stores the result in dst. If dst is not initialized
+// it is allocated automatically. B and dst may be the same matrix.
+// Solve returns ErrSingular if the factorized matrix is singular.
+func (lu *LU) Solve(dst *DenseM, trans bool, B Matrix) error {
+	n := lu.lu.r
+	br, bc := B.Dims()
+	if br != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(br, bc, nil)
+	}
+	if r, c := dst.Dims(); r != br || c != bc {
+		panic(ErrDim)
+	}
+	if lu.isSingular() {
+		return ErrSingular
+	}
+	if dst != B {
+		dst.Copy(B)
+	}
+	for j := 0; j < bc; j++ {
+		lu.solveInPlace(dst.ColView(j), trans)
+	}
+	return nil
+}
+
+// SolveVec solves the system A*x = b, or A^T*x = b if trans is true, using the
+// factorization of A and stores the result in dst. If dst is not initialized
+// it is allocated automatically. b and dst may be the same vector.
+// SolveVec returns ErrSingular if the factorized matrix is singular.
+func (lu *LU) SolveVec(dst *DenseV, trans bool, b Vector) error {
+	n := lu.lu.r
+	if b.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if lu.isSingular() {
+		return ErrSingular
+	}
+	if dst != b {
+		dst.CopyVec(b)
+	}
+	lu.solveInPlace(dst, trans)
+	return nil
+}
+
+// Inverse computes the inverse of the factorized matrix and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// Inverse returns ErrSingular if the factorized matrix is singular.
+func (lu *LU) Inverse(dst *DenseM) error {
+	n := lu.lu.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	if lu.isSingular() {
+		return ErrSingular
+	}
+	dst.Copy(Eye(n))
+	return lu.Solve(dst, false, dst)
+}
+
+// solveInPlace overwrites x with the solution of A*x = x or A^T*x = x.
+// The factorization must not be singular.
+func (lu *LU) solveInPlace(x *DenseV, trans bool) {
+	luSolve(lu.lu.data, lu.lu.stride, lu.lu.r, lu.pivot, x.data, x.incMinusOne+1, trans)
+}
+
+// luFactorize overwrites the nxn matrix a, stored row major with the given
+// stride, with its LU factorization with partial pivoting. The row swapped
+// with row k in step k is stored in pivot[k] and the number of swaps is returned.
+// luFactorize is shared by LU and LUOf.
+func luFactorize[T Float](a []T, stride, n int, pivot []int) (swaps int) {
+	for k := 0; k < n; k++ {
+		// Find the largest magnitude element in column k on or below the diagonal.
+		p := k
+		pmax := absOf(a[k*stride+k])
+		for i := k + 1; i < n; i++ {
+			v := absOf(a[i*stride+k])
+			if v > pmax {
+				p = i
+				pmax = v
+			}
+		}
+		pivot[k] = p
+		if p != k {
+			for j := 0; j < n; j++ {
+				a[p*stride+j], a[k*stride+j] = a[k*stride+j], a[p*stride+j]
+			}
+			swaps++
+		}
+		pv := a[k*stride+k]
+		if pv == 0 {
+			// Column is zero below diagonal, nothing to eliminate.
+			continue
+		}
+		kidx := k * stride
+		for i := k + 1; i < n; i++ {
+			iidx := i * stride
+			l := a[iidx+k] / pv
+			a[iidx+k] = l
+			if l == 0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				a[iidx+j] -= l * a[kidx+j]
+			}
+		}
+	}
+	return swaps
+}
+
+// luSolve overwrites the vector x of length n, whose elements are inc apart,
+// with the solution of A*x = x, or A^T*x = x if trans is true, where a and
+// pivot hold the factorization of A computed by luFactorize.
+func luSolve[T Float](a []T, stride, n int, pivot []int, x []T, inc int, trans bool) {
+	if !trans {
+		// Solve L*U*x = P*b.
+		for k, p := range pivot {
+			if p != k {
+				x[k*inc], x[p*inc] = x[p*inc], x[k*inc]
+			}
+		}
+		for i := 1; i < n; i++ {
+			sum := x[i*inc]
+			for j := 0; j < i; j++ {
+				sum -= a[i*stride+j] * x[j*inc]
+			}
+			x[i*inc] = sum
+		}
+		for i := n - 1; i >= 0; i-- {
+			sum := x[i*inc]
+			for j := i + 1; j < n; j++ {
+				sum -= a[i*stride+j] * x[j*inc]
+			}
+			x[i*inc] = sum / a[i*stride+i]
+		}
+		return
+	}
+	// Solve U^T*L^T*(P*x) = b.
+	for i := 0; i < n; i++ {
+		sum := x[i*inc]
+		for j := 0; j < i; j++ {
+			sum -= a[j*stride+i] * x[j*inc]
+		}
+		x[i*inc] = sum / a[i*stride+i]
+	}
+	for i := n - 2; i >= 0; i-- {
+		sum := x[i*inc]
+		for j := i + 1; j < n; j++ {
+			sum -= a[j*stride+i] * x[j*inc]
+		}
+		x[i*inc] = sum
+	}
+	for k := n - 1; k >= 0; k-- {
+		if p := pivot[k]; p != k {
+			x[k*inc], x[p*inc] = x[p*inc], x[k*inc]
+		}
+	}
+}
+
+// hasZeroDiag reports whether the leading n diagonal elements of the matrix
+// a stored row major with the given stride include an exact zero.
+func hasZeroDiag[T Float](a []T, stride, n int) bool {
+	for i := 0; i < n; i++ {
+		if a[i*stride+i] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// luRcond estimates the reciprocal of the 1-norm condition number of the nxn
+// matrix with 1-norm anorm whose factorization computed by luFactorize is held
+// in a and pivot. It returns 0 if the matrix is singular.
+func luRcond[T Float](a []T, stride, n int, pivot []int, anorm float64) float64 {
+	if n == 0 {
+		return 1
+	}
+	if hasZeroDiag(a, stride, n) || anorm == 0 {
+		return 0
+	}
+	ainvnorm := invNorm1(n, func(x []T, trans bool) { luSolve(a, stride, n, pivot, x, 1, trans) })
+	return 1 / (anorm * ainvnorm)
+}
+
+// LUOf is the generic counterpart of LU for matrices with elements of type T.
+// It shares its implementation with LU. Unlike LU, whose solves only fail for
+// an exactly singular matrix, the solves of LUOf return a ConditionError if
+// the reciprocal condition number is below the machine epsilon of T, since
+// float32 results lose all accuracy at much smaller condition numbers.
+type LUOf[T Float] struct {
+	lu    DenseMOf[T]
+	pivot []int
+	swaps int
+	// rcond is the estimated reciprocal 1-norm condition number of the
+	// factorized matrix.
+	rcond float64
+}
+
+// Factorize computes the LU factorization of the square matrix A and stores
+// the result in the receiver. Factorize panics with ErrDim if A is not square.
+// Factorization of an ill-conditioned matrix succeeds, but subsequent solves
+// will fail with a ConditionError.
+func (lu *LUOf[T]) Factorize(A MatrixOf[T]) {
+	n, c := A.Dims()
+	if n != c {
+		panic(ErrDim)
+	}
+	if lu.lu.r != n || lu.lu.c != n {
+		lu.lu = *NewDenseMatrixOf[T](n, n, nil)
+	}
+	lu.lu.Copy(A)
+	if cap(lu.pivot) < n {
+		lu.pivot = make([]int, n)
+	}
+	lu.pivot = lu.pivot[:n]
+	anorm := float64(NormOf(A, 1))
+	lu.swaps = luFactorize(lu.lu.data, lu.lu.stride, n, lu.pivot)
+	lu.rcond = luRcond(lu.lu.data, lu.lu.stride, n, lu.pivot, anorm)
+}
+
+// check returns a ConditionError if the factorized matrix is too ill-conditioned
+// to solve with in the precision of T.
+func (lu *LUOf[T]) check() error {
+	if lu.rcond < epsilonOf[T]() || math.IsNaN(lu.rcond) {
+		return ConditionError(lu.rcond)
+	}
+	return nil
+}
+
+// Det returns the determinant of the factorized matrix.
+func (lu *LUOf[T]) Det() T {
+	var det T = 1
+	if lu.swaps%2 == 1 {
+		det = -1
+	}
+	for i := 0; i < lu.lu.r; i++ {
+		det *= lu.lu.data[i*lu.lu.stride+i]
+	}
+	return det
+}
+
+// LogDet returns the log of the absolute value of the determinant of the
+// factorized matrix and its sign. The determinant is equal to sign*exp(log).
+func (lu *LUOf[T]) LogDet() (log float64, sign float64) {
+	sign = 1
+	if lu.swaps%2 == 1 {
+		sign = -1
+	}
+	for i := 0; i < lu.lu.r; i++ {
+		v := float64(lu.lu.data[i*lu.lu.stride+i])
+		if v < 0 {
+			sign = -sign
+		}
+		log += math.Log(math.Abs(v))
+	}
+	return log, sign
+}
+
+// Cond returns an estimate of the 1-norm condition number of the factorized matrix.
+// The returned value is +Inf if the matrix is singular.
+func (lu *LUOf[T]) Cond() float64 {
+	if lu.rcond == 0 {
+		return math.Inf(1)
+	}
+	return 1 / lu.rcond
+}
+
+// Solve solves the system A*X = B, or A^T*X = B if trans is true, using the
+// factorization of A and stores the result in dst. If dst is not initialized
+// it is allocated automatically. B and dst may be the same matrix.
+// Solve returns a ConditionError if the factorized matrix is singular or
+// nearly so.
+func (lu *LUOf[T]) Solve(dst *DenseMOf[T], trans bool, B MatrixOf[T]) error {
+	n := lu.lu.r
+	br, bc := B.Dims()
+	if br != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](br, bc, nil)
+	}
+	if r, c := dst.Dims(); r != br || c != bc {
+		panic(ErrDim)
+	}
+	if err := lu.check(); err != nil {
+		return err
+	}
+	if dst != B {
+		dst.Copy(B)
+	}
+	for j := 0; j < bc; j++ {
+		luSolve(lu.lu.data, lu.lu.stride, n, lu.pivot, dst.data[j:], dst.stride, trans)
+	}
+	return nil
+}
+
+// SolveVec solves the system A*x = b, or A^T*x = b if trans is true, using the
+// factorization of A and stores the result in dst. If dst is not initialized
+// it is allocated automatically. b and dst may be the same vector.
+// SolveVec returns a ConditionError if the factorized matrix is singular or
+// nearly so.
+func (lu *LUOf[T]) SolveVec(dst *DenseVOf[T], trans bool, b VectorOf[T]) error {
+	n := lu.lu.r
+	if b.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVectorOf[T](n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if err := lu.check(); err != nil {
+		return err
+	}
+	if dst != b {
+		dst.CopyVec(b)
+	}
+	luSolve(lu.lu.data, lu.lu.stride, n, lu.pivot, dst.data, dst.incMinusOne+1, trans)
+	return nil
+}
+
+// Inverse computes the inverse of the factorized matrix and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// Inverse returns a ConditionError if the factorized matrix is singular or
+// nearly so.
+func (lu *LUOf[T]) Inverse(dst *DenseMOf[T]) error {
+	n := lu.lu.r
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](n, n, nil)
+	}
+	if r, c := dst.Dims(); r != n || c != n {
+		panic(ErrDim)
+	}
+	if err := lu.check(); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			var v T
+			if i == j {
+				v = 1
+			}
+			dst.data[i*dst.stride+j] = v
+		}
+	}
+	return lu.Solve(dst, false, dst)
+}
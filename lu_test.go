@@ -0,0 +1,99 @@
+package lap
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLUFactorize(t *testing.T) {
+	var lu LU
+	lu.Factorize(magic3)
+	var L, U, LU, PA DenseM
+	lu.LTo(&L)
+	lu.UTo(&U)
+	LU.Mul(&L, &U)
+	perm := lu.Pivot(nil)
+	PA.Copy(Slice(magic3, perm, nil))
+	if !matrixEqualTol(&LU, &PA, 1e-12) {
+		t.Errorf("P*A != L*U:\n%v\n%v", Formatted(&PA), Formatted(&LU))
+	}
+	if det := lu.Det(); !almostEqual(det, -360, 1e-10) {
+		t.Errorf("expected determinant -360, got %g", det)
+	}
+	logdet, sign := lu.LogDet()
+	if sign != -1 || !almostEqual(logdet, math.Log(360), 1e-12) {
+		t.Errorf("bad log determinant %g with sign %g", logdet, sign)
+	}
+}
+
+func TestLUSolve(t *testing.T) {
+	const n = 6
+	rng := rand.New(rand.NewSource(1))
+	A := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	want := NewDenseVector(n, randomSlice(rng, n))
+	var lu LU
+	lu.Factorize(A)
+	for _, trans := range []bool{false, true} {
+		var b, x DenseV
+		if trans {
+			b.MulVec(T(A), want)
+		} else {
+			b.MulVec(A, want)
+		}
+		err := lu.SolveVec(&x, trans, &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !vectorEqualTol(&x, want, 1e-10) {
+			t.Errorf("trans=%v: got solution %v, want %v", trans, x.data, want.data)
+		}
+	}
+
+	var inv, ident DenseM
+	err := lu.Inverse(&inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident.Mul(A, &inv)
+	if !matrixEqualTol(&ident, Eye(n), 1e-10) {
+		t.Errorf("A*inv(A) is not identity:\n%v", Formatted(&ident))
+	}
+
+	var X DenseM
+	B := NewDenseMatrix(n, 2, randomSlice(rng, 2*n))
+	err = lu.Solve(&X, false, B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var AX DenseM
+	AX.Mul(A, &X)
+	if !matrixEqualTol(&AX, B, 1e-10) {
+		t.Error("A*X != B")
+	}
+	if cond := lu.Cond(); cond < 1 || math.IsInf(cond, 1) {
+		t.Errorf("bad condition number estimate %g", cond)
+	}
+}
+
+func TestLUSingular(t *testing.T) {
+	A := NewDenseMatrix(3, 3, []float64{
+		1, 2, 3,
+		2, 4, 6,
+		1, 0, 1,
+	})
+	var lu LU
+	lu.Factorize(A)
+	var x DenseV
+	err := lu.SolveVec(&x, false, NewDenseVector(3, []float64{1, 2, 3}))
+	if !errors.Is(err, ErrSingular) {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+	if det := lu.Det(); det != 0 {
+		t.Errorf("expected zero determinant, got %g", det)
+	}
+	if cond := lu.Cond(); !math.IsInf(cond, 1) {
+		t.Errorf("expected infinite condition number, got %g", cond)
+	}
+}
@@ -0,0 +1,376 @@
+package lap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = &DenseM{}
+	_ encoding.BinaryUnmarshaler = &DenseM{}
+	_ encoding.BinaryMarshaler   = &DenseV{}
+	_ encoding.BinaryUnmarshaler = &DenseV{}
+	_ encoding.BinaryMarshaler   = &Sparse{}
+	_ encoding.BinaryUnmarshaler = &Sparse{}
+)
+
+// ErrBadEncoding is returned when binary data can not be decoded into a matrix.
+var ErrBadEncoding = errors.New("invalid binary encoding")
+
+// binaryVersion is the current version of the binary format.
+const binaryVersion = 1
+
+// Magic numbers identifying the type encoded in binary data.
+var (
+	magicDenseM = [4]byte{'L', 'A', 'P', 'M'}
+	magicDenseV = [4]byte{'L', 'A', 'P', 'V'}
+	magicSparse = [4]byte{'L', 'A', 'P', 'S'}
+)
+
+// binaryHeader is the fixed size header of the binary format.
+// All fields are encoded in little-endian byte order.
+//
+//	magic   [4]byte  type identifier
+//	version uint32   format version
+//	rows    int64    number of rows
+//	cols    int64    number of columns
+//	nnz     int64    number of stored elements
+//
+// The header is followed by the payload. Dense types store nnz = rows*cols
+// float64 values in row-major order. Sparse stores nnz (int64 row, int64 col,
+// float64 value) triplets sorted by row and then column.
+type binaryHeader struct {
+	magic      [4]byte
+	version    uint32
+	rows, cols int64
+	nnz        int64
+}
+
+const binaryHeaderSize = 4 + 4 + 3*8
+
+func (h binaryHeader) write(w io.Writer) (int64, error) {
+	var buf [binaryHeaderSize]byte
+	copy(buf[:4], h.magic[:])
+	binary.LittleEndian.PutUint32(buf[4:], h.version)
+	binary.LittleEndian.PutUint64(buf[8:], uint64(h.rows))
+	binary.LittleEndian.PutUint64(buf[16:], uint64(h.cols))
+	binary.LittleEndian.PutUint64(buf[24:], uint64(h.nnz))
+	n, err := w.Write(buf[:])
+	return int64(n), err
+}
+
+// read reads the header from r and checks it matches the magic number and the
+// current version.
+func (h *binaryHeader) read(r io.Reader, magic [4]byte) (int64, error) {
+	var buf [binaryHeaderSize]byte
+	n, err := io.ReadFull(r, buf[:])
+	if err != nil {
+		return int64(n), err
+	}
+	copy(h.magic[:], buf[:4])
+	h.version = binary.LittleEndian.Uint32(buf[4:])
+	h.rows = int64(binary.LittleEndian.Uint64(buf[8:]))
+	h.cols = int64(binary.LittleEndian.Uint64(buf[16:]))
+	h.nnz = int64(binary.LittleEndian.Uint64(buf[24:]))
+	switch {
+	case h.magic != magic:
+		return int64(n), fmt.Errorf("%w: bad magic number %q", ErrBadEncoding, h.magic[:])
+	case h.version != binaryVersion:
+		return int64(n), fmt.Errorf("%w: unsupported version %d", ErrBadEncoding, h.version)
+	case h.rows < 0 || h.cols < 0 || h.nnz < 0 || int64(int(h.rows)) != h.rows || int64(int(h.cols)) != h.cols:
+		return int64(n), fmt.Errorf("%w: bad dimensions %dx%d", ErrBadEncoding, h.rows, h.cols)
+	}
+	return int64(n), nil
+}
+
+// checkPayload returns an error if the payload of nnz elements of elemSize
+// bytes each is too large to be addressed.
+func (h binaryHeader) checkPayload(elemSize int64) error {
+	if h.nnz > math.MaxInt64/elemSize || int64(int(h.nnz*elemSize)) != h.nnz*elemSize {
+		return fmt.Errorf("%w: element count %d too large", ErrBadEncoding, h.nnz)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the receiver into a binary form and returns the result.
+// The payload is compacted so that the stride of the receiver is not stored.
+func (m *DenseM) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(binaryHeaderSize + 8*m.r*m.c)
+	_, err := m.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// WriteTo writes the binary form of the receiver to w and returns the number
+// of bytes written.
+func (m *DenseM) WriteTo(w io.Writer) (int64, error) {
+	h := binaryHeader{magic: magicDenseM, version: binaryVersion, rows: int64(m.r), cols: int64(m.c), nnz: int64(m.r * m.c)}
+	n, err := h.write(w)
+	if err != nil {
+		return n, err
+	}
+	buf := make([]byte, 8*m.c)
+	for i := 0; i < m.r; i++ {
+		putFloats(buf, m.data[i*m.stride:i*m.stride+m.c])
+		nn, err := w.Write(buf)
+		n += int64(nn)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// UnmarshalBinary decodes the binary form into the receiver. If the receiver
+// is empty it is allocated, otherwise the encoded dimensions must match those
+// of the receiver and ErrDim is returned if they do not.
+func (m *DenseM) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := m.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrBadEncoding, r.Len())
+	}
+	return nil
+}
+
+// ReadFrom reads the binary form of a matrix from r into the receiver and
+// returns the number of bytes read. See UnmarshalBinary for how the
+// receiver dimensions are handled.
+func (m *DenseM) ReadFrom(r io.Reader) (int64, error) {
+	var h binaryHeader
+	n, err := h.read(r, magicDenseM)
+	if err != nil {
+		return n, err
+	}
+	rows, cols := int(h.rows), int(h.cols)
+	if h.nnz != h.rows*h.cols || (cols != 0 && h.nnz/h.cols != h.rows) {
+		return n, fmt.Errorf("%w: bad element count %d for %dx%d matrix", ErrBadEncoding, h.nnz, rows, cols)
+	}
+	if err := h.checkPayload(8); err != nil {
+		return n, err
+	}
+	if m.data != nil && (m.r != rows || m.c != cols) {
+		return n, ErrDim
+	}
+	data, nn, err := readFloats(r, int(h.nnz))
+	n += nn
+	if err != nil {
+		return n, err
+	}
+	if m.data == nil {
+		*m = *NewDenseMatrix(rows, cols, data)
+		return n, nil
+	}
+	for i := 0; i < rows; i++ {
+		copy(m.data[i*m.stride:i*m.stride+cols], data[i*cols:])
+	}
+	return n, nil
+}
+
+// MarshalBinary encodes the receiver into a binary form and returns the result.
+// The payload is compacted so that the increment of the receiver is not stored.
+func (v *DenseV) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(binaryHeaderSize + 8*v.Len())
+	_, err := v.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// WriteTo writes the binary form of the receiver to w and returns the number
+// of bytes written.
+func (v *DenseV) WriteTo(w io.Writer) (int64, error) {
+	l := v.Len()
+	h := binaryHeader{magic: magicDenseV, version: binaryVersion, rows: int64(l), cols: 1, nnz: int64(l)}
+	n, err := h.write(w)
+	if err != nil {
+		return n, err
+	}
+	buf := make([]byte, 8*l)
+	for i := 0; i < l; i++ {
+		binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v.AtVec(i)))
+	}
+	nn, err := w.Write(buf)
+	return n + int64(nn), err
+}
+
+// UnmarshalBinary decodes the binary form into the receiver. If the receiver
+// is empty it is allocated, otherwise the encoded length must match that
+// of the receiver and ErrDim is returned if it does not.
+func (v *DenseV) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := v.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrBadEncoding, r.Len())
+	}
+	return nil
+}
+
+// ReadFrom reads the binary form of a vector from r into the receiver and
+// returns the number of bytes read. See UnmarshalBinary for how the
+// receiver length is handled.
+func (v *DenseV) ReadFrom(r io.Reader) (int64, error) {
+	var h binaryHeader
+	n, err := h.read(r, magicDenseV)
+	if err != nil {
+		return n, err
+	}
+	if h.cols != 1 || h.nnz != h.rows {
+		return n, fmt.Errorf("%w: bad vector dimensions %dx%d", ErrBadEncoding, h.rows, h.cols)
+	}
+	if err := h.checkPayload(8); err != nil {
+		return n, err
+	}
+	l := int(h.rows)
+	if v.data != nil && v.Len() != l {
+		return n, ErrDim
+	}
+	data, nn, err := readFloats(r, l)
+	n += nn
+	if err != nil {
+		return n, err
+	}
+	if v.data == nil {
+		*v = *NewDenseVector(l, data)
+		return n, nil
+	}
+	for i, x := range data {
+		v.SetVec(i, x)
+	}
+	return n, nil
+}
+
+// MarshalBinary encodes the receiver into a binary form and returns the result.
+// Only the non-zero entries are stored.
+func (s *Sparse) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(binaryHeaderSize + 24*len(s.m))
+	_, err := s.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// WriteTo writes the binary form of the receiver to w and returns the number
+// of bytes written. Entries are written sorted by row and then column so the
+// output is deterministic.
+func (s *Sparse) WriteTo(w io.Writer) (int64, error) {
+	c := NewCSR(s)
+	h := binaryHeader{magic: magicSparse, version: binaryVersion, rows: int64(s.r), cols: int64(s.c), nnz: int64(c.CountNonZero())}
+	n, err := h.write(w)
+	if err != nil {
+		return n, err
+	}
+	var buf [24]byte
+	c.DoNonZero(func(i, j int, v float64) {
+		if err != nil {
+			return
+		}
+		binary.LittleEndian.PutUint64(buf[0:], uint64(i))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(j))
+		binary.LittleEndian.PutUint64(buf[16:], math.Float64bits(v))
+		var nn int
+		nn, err = w.Write(buf[:])
+		n += int64(nn)
+	})
+	return n, err
+}
+
+// UnmarshalBinary decodes the binary form into the receiver, replacing its
+// entries. If the receiver has no dimensions it is resized to the encoded
+// dimensions, otherwise they must match and ErrDim is returned if they do not.
+func (s *Sparse) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := s.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrBadEncoding, r.Len())
+	}
+	return nil
+}
+
+// ReadFrom reads the binary form of a sparse matrix from r into the receiver
+// and returns the number of bytes read. See UnmarshalBinary for how the
+// receiver dimensions are handled.
+func (s *Sparse) ReadFrom(r io.Reader) (int64, error) {
+	var h binaryHeader
+	n, err := h.read(r, magicSparse)
+	if err != nil {
+		return n, err
+	}
+	if err := h.checkPayload(24); err != nil {
+		return n, err
+	}
+	rows, cols := int(h.rows), int(h.cols)
+	if s.r == 0 && s.c == 0 {
+		*s = *NewSparse(rows, cols)
+	}
+	if s.r != rows || s.c != cols {
+		return n, ErrDim
+	}
+	m := make(map[[2]int]float64)
+	var buf [24]byte
+	for k := int64(0); k < h.nnz; k++ {
+		nn, err := io.ReadFull(r, buf[:])
+		n += int64(nn)
+		if err != nil {
+			return n, unexpectedEOF(err)
+		}
+		i := binary.LittleEndian.Uint64(buf[0:])
+		j := binary.LittleEndian.Uint64(buf[8:])
+		v := math.Float64frombits(binary.LittleEndian.Uint64(buf[16:]))
+		if i >= uint64(rows) || j >= uint64(cols) {
+			return n, fmt.Errorf("%w: entry (%d, %d) out of bounds", ErrBadEncoding, i, j)
+		}
+		if v != 0 {
+			m[[2]int{int(i), int(j)}] = v
+		}
+	}
+	s.m = m
+	return n, nil
+}
+
+func putFloats(dst []byte, src []float64) {
+	for i, v := range src {
+		binary.LittleEndian.PutUint64(dst[8*i:], math.Float64bits(v))
+	}
+}
+
+// readFloats reads n float64 values from r and returns them with the number
+// of bytes read. The values are read in bounded chunks so that a corrupt
+// element count can not cause a large allocation before the payload is
+// found to be missing.
+func readFloats(r io.Reader, n int) ([]float64, int64, error) {
+	const chunk = 1024
+	var buf [8 * chunk]byte
+	data := make([]float64, 0, min(n, chunk))
+	var read int64
+	for len(data) < n {
+		k := min(n-len(data), chunk)
+		nn, err := io.ReadFull(r, buf[:8*k])
+		read += int64(nn)
+		if err != nil {
+			return nil, read, unexpectedEOF(err)
+		}
+		for i := 0; i < k; i++ {
+			data = append(data, math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:])))
+		}
+	}
+	return data, read, nil
+}
+
+// unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF since the payload
+// is known to be incomplete.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package lap
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestMarshalBinaryDense(t *testing.T) {
+	data := []float64{1, -2, math.Pi, math.Inf(1), 0, math.SmallestNonzeroFloat64}
+	// A view has a stride larger than its column count.
+	big := NewDenseMatrix(3, 4, nil)
+	big.DoSet(func(i, j int, _ float64) float64 { return float64(10*i + j) })
+	view := big.Slice(1, 3, 1, 3)
+	for _, m := range []*DenseM{NewDenseMatrix(2, 3, data), NewDenseMatrix(0, 0, nil), view} {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, c := m.Dims()
+		if len(b) != binaryHeaderSize+8*r*c {
+			t.Errorf("stride not compacted: got %d bytes", len(b))
+		}
+		var got DenseM
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !matrixEqual(&got, m) {
+			t.Errorf("round trip mismatch: got %v, want %v", got.data, m.data)
+		}
+	}
+
+	// Decoding into an existing view writes through.
+	src := NewDenseMatrix(2, 2, []float64{7, 8, 9, 10})
+	var buf bytes.Buffer
+	if _, err := src.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := view.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if big.At(1, 1) != 7 || big.At(2, 2) != 10 || big.At(1, 3) != 13 {
+		t.Errorf("view not written through: %v", big.data)
+	}
+	b, _ := src.MarshalBinary()
+	if err := NewDenseMatrix(3, 3, nil).UnmarshalBinary(b); err != ErrDim {
+		t.Errorf("expected ErrDim, got %v", err)
+	}
+
+	v := NewDenseVector(len(data), data)
+	b, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotv DenseV
+	if err := gotv.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !vectorEqual(&gotv, v) {
+		t.Errorf("vector round trip mismatch: got %v", gotv.data)
+	}
+	// The matrix and vector encodings are not interchangeable.
+	var m DenseM
+	if err := m.UnmarshalBinary(b); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("expected ErrBadEncoding, got %v", err)
+	}
+}
+
+func TestMarshalBinarySparse(t *testing.T) {
+	s := NewSparse(5, 4)
+	s.Set(4, 0, -1.5)
+	s.Set(0, 3, 2)
+	s.Set(2, 2, math.NaN())
+	b, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != binaryHeaderSize+3*24 {
+		t.Errorf("unexpected encoded length %d", len(b))
+	}
+	// Encoding is deterministic.
+	for i := 0; i < 5; i++ {
+		b2, _ := s.MarshalBinary()
+		if !bytes.Equal(b, b2) {
+			t.Fatal("encoding is not deterministic")
+		}
+	}
+	var got Sparse
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if r, c := got.Dims(); r != 5 || c != 4 || got.CountNonZero() != 3 {
+		t.Fatalf("got %dx%d matrix with %d entries", r, c, got.CountNonZero())
+	}
+	if got.At(4, 0) != -1.5 || got.At(0, 3) != 2 || !math.IsNaN(got.At(2, 2)) {
+		t.Error("round trip mismatch")
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	m := NewDenseMatrix(2, 2, []float64{1, 2, 3, 4})
+	b, _ := m.MarshalBinary()
+
+	var got DenseM
+	if err := got.UnmarshalBinary(b[:len(b)-3]); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated payload: got %v", err)
+	}
+	got = DenseM{}
+	if err := got.UnmarshalBinary(b[:10]); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated header: got %v", err)
+	}
+	got = DenseM{}
+	if err := got.UnmarshalBinary(append(b, 0)); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("trailing data: got %v", err)
+	}
+	bad := append([]byte(nil), b...)
+	bad[4] = 2
+	got = DenseM{}
+	if err := got.UnmarshalBinary(bad); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("bad version: got %v", err)
+	}
+
+	s := NewSparse(2, 2)
+	s.Set(1, 1, 1)
+	b, _ = s.MarshalBinary()
+	b[binaryHeaderSize] = 9
+	var gots Sparse
+	if err := gots.UnmarshalBinary(b); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("out of bounds entry: got %v", err)
+	}
+}
+
+func TestUnmarshalBinaryMaliciousHeader(t *testing.T) {
+	header := func(magic [4]byte, rows, cols, nnz int64) []byte {
+		var buf bytes.Buffer
+		binaryHeader{magic: magic, version: binaryVersion, rows: rows, cols: cols, nnz: nnz}.write(&buf)
+		return buf.Bytes()
+	}
+	for _, test := range []struct {
+		name string
+		data []byte
+		dst  encoding.BinaryUnmarshaler
+		want error
+	}{
+		// Element counts whose payload size overflows.
+		{"dense oversized", header(magicDenseM, 1<<31, 1<<31, 1<<62), &DenseM{}, ErrBadEncoding},
+		{"vector oversized", header(magicDenseV, 1<<62, 1, 1<<62), &DenseV{}, ErrBadEncoding},
+		{"sparse oversized", header(magicSparse, 2, 2, 1<<62), &Sparse{}, ErrBadEncoding},
+		// Plausible element counts without the payload to back them.
+		{"dense truncated", header(magicDenseM, 1<<20, 1<<20, 1<<40), &DenseM{}, io.ErrUnexpectedEOF},
+		{"vector truncated", header(magicDenseV, 1<<40, 1, 1<<40), &DenseV{}, io.ErrUnexpectedEOF},
+		{"sparse truncated", header(magicSparse, 1<<40, 1<<40, 1<<40), &Sparse{}, io.ErrUnexpectedEOF},
+	} {
+		if err := test.dst.UnmarshalBinary(test.data); !errors.Is(err, test.want) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.want)
+		}
+	}
+}
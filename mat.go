@@ -14,6 +14,35 @@ var (
 	errImmutable   = errors.New("immutable matrix")
 )
 
+// ConditionError is returned when a matrix is singular or too ill-conditioned
+// for a computed result to be trusted. Its value is the estimated reciprocal
+// condition number of the matrix, zero for an exactly singular matrix.
+// ConditionError wraps ErrSingular.
+type ConditionError float64
+
+func (c ConditionError) Error() string {
+	return fmt.Sprintf("%v: reciprocal condition number %.4g", ErrSingular, float64(c))
+}
+
+// Unwrap returns ErrSingular.
+func (c ConditionError) Unwrap() error { return ErrSingular }
+
+// ParseError is returned when a matrix cannot be decoded from a text
+// representation. Line is the one-based line number of the input on which
+// the error occurred.
+type ParseError struct {
+	Format string
+	Line   int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: line %d: %v", e.Format, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
 type Matrix interface {
 	At(i, j int) float64
 	Dims() (r, c int)
@@ -1,6 +1,7 @@
 package lap
 
 import (
+	"errors"
 	"math"
 	"reflect"
 	"testing"
@@ -288,21 +289,85 @@ func TestSliceVec(t *testing.T) {
 	}
 }
 
-// func TestSquareMatrixInvert(t *testing.T) {
-// 	inp := NewDenseMatrix(2, 2, []float64{
-// 		1, 2,
-// 		3, 4,
-// 	})
-// 	exp := NewDenseMatrix(2, 2, []float64{
-// 		-2, 1,
-// 		1.5, -0.5,
-// 	})
-// 	var inv DenseM
-// 	err := inv.invertSquare(inp, nil)
-// 	if err != nil {
-// 		t.Fatal(err)
-// 	}
-// 	if !matrixEqual(exp, inv) {
-// 		t.Error("matrix inversion did not match expectation")
-// 	}
-// }
+func TestSquareMatrixInvert(t *testing.T) {
+	inp := NewDenseMatrix(2, 2, []float64{
+		1, 2,
+		3, 4,
+	})
+	exp := NewDenseMatrix(2, 2, []float64{
+		-2, 1,
+		1.5, -0.5,
+	})
+	var inv DenseM
+	err := inv.Inverse(inp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matrixEqualTol(exp, &inv, 1e-15) {
+		t.Error("matrix inversion did not match expectation")
+	}
+
+	// A badly scaled but perfectly conditioned matrix must invert.
+	var scaled DenseM
+	scaled.Scale(1e-20, magic3)
+	scratch := make([]float64, 3*6)
+	var sinv DenseM
+	err = sinv.Inverse(&scaled, scratch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ident DenseM
+	ident.Mul(&scaled, &sinv)
+	if !matrixEqualTol(&ident, Eye(3), 1e-14) {
+		t.Error("scaled matrix inversion did not match expectation")
+	}
+
+	// The Hilbert matrix is notoriously ill-conditioned.
+	const n = 14
+	hilbert := NewDenseMatrix(n, n, nil)
+	hilbert.DoSet(func(i, j int, _ float64) float64 { return 1 / float64(i+j+1) })
+	var hinv DenseM
+	err = hinv.Inverse(hilbert, nil)
+	var cerr ConditionError
+	if !errors.As(err, &cerr) || !errors.Is(err, ErrSingular) {
+		t.Fatalf("expected ConditionError, got %v", err)
+	}
+	if cerr >= epsilon {
+		t.Errorf("expected small reciprocal condition number, got %g", float64(cerr))
+	}
+
+	singular := NewDenseMatrix(2, 2, []float64{
+		1, 2,
+		2, 4,
+	})
+	err = inv.Inverse(singular, nil)
+	if !errors.As(err, &cerr) || cerr != 0 {
+		t.Errorf("expected zero ConditionError, got %v", err)
+	}
+
+	// Inverting in place must estimate the condition from the original matrix.
+	for _, inPlace := range []bool{false, true} {
+		A := NewDenseMatrix(2, 2, []float64{
+			1e17, 0,
+			0, 1,
+		})
+		out := &DenseM{}
+		if inPlace {
+			out = A
+		}
+		err = out.Inverse(A, nil)
+		if !errors.As(err, &cerr) || !almostEqual(float64(cerr), 1e-17, 1e-30) {
+			t.Errorf("inPlace=%v: expected ConditionError of 1e-17, got %v", inPlace, err)
+		}
+	}
+	inPlace := NewDenseMatrix(2, 2, []float64{
+		1, 2,
+		3, 4,
+	})
+	if err = inPlace.Inverse(inPlace, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !matrixEqualTol(exp, inPlace, 1e-15) {
+		t.Error("in place matrix inversion did not match expectation")
+	}
+}
@@ -0,0 +1,112 @@
+package lap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMATLAB parses a matrix written in MATLAB literal syntax such as
+//
+//	[1 2.5, -3; 4e-3 Inf NaN]
+//
+// Columns are separated by commas or white space and rows by semicolons or
+// newlines. Line continuations (...) and % comments are ignored. The output
+// of FormatMATLAB, including the multi-line form produced with the '#' flag,
+// is parsed back into an equal matrix when printed with the %v verb.
+//
+// Parse errors are returned as a *ParseError holding the line of s on which
+// they occurred.
+func ParseMATLAB(s string) (*DenseM, error) {
+	var (
+		data           []float64
+		rows, cols, n  int
+		line           = 1
+		opened, closed bool
+	)
+	errorf := func(format string, args ...interface{}) error {
+		return &ParseError{Format: "matlab", Line: line, Err: fmt.Errorf(format, args...)}
+	}
+	endRow := func() error {
+		if n == 0 {
+			return nil
+		}
+		if rows == 0 {
+			cols = n
+		} else if n != cols {
+			return errorf("row %d has %d columns, want %d", rows+1, n, cols)
+		}
+		rows++
+		n = 0
+		return nil
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+			if opened && !closed {
+				if err := endRow(); err != nil {
+					return nil, err
+				}
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '%' || strings.HasPrefix(s[i:], "..."):
+			// Skip to the end of the line. A continuation also
+			// consumes the newline so the row does not end.
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				i = len(s)
+				break
+			}
+			i += end
+			if c == '.' {
+				i++
+				line++
+			}
+		case closed:
+			return nil, errorf("unexpected %q after closing bracket", c)
+		case c == '[':
+			if opened {
+				return nil, errorf("nested brackets are not supported")
+			}
+			opened = true
+			i++
+		case !opened:
+			return nil, errorf("expected opening bracket, got %q", c)
+		case c == ']':
+			if err := endRow(); err != nil {
+				return nil, err
+			}
+			closed = true
+			i++
+		case c == ';':
+			if err := endRow(); err != nil {
+				return nil, err
+			}
+			i++
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t\r\n,;[]%", rune(s[end])) {
+				end++
+			}
+			tok := s[i:end]
+			v, err := strconv.ParseFloat(tok, 64)
+			if err != nil {
+				return nil, errorf("invalid number %q", tok)
+			}
+			data = append(data, v)
+			n++
+			i = end
+		}
+	}
+	if !closed {
+		return nil, errorf("missing closing bracket")
+	}
+	if data == nil {
+		data = []float64{}
+	}
+	return NewDenseMatrix(rows, cols, data), nil
+}
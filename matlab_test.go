@@ -0,0 +1,78 @@
+package lap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestParseMATLAB(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		want  *DenseM
+	}{
+		{"[1 2; 3 4]", NewDenseMatrix(2, 2, []float64{1, 2, 3, 4})},
+		{"  [1, 2,3 ;4 ,5 6]  ", NewDenseMatrix(2, 3, []float64{1, 2, 3, 4, 5, 6})},
+		{"[1e3 -2.5E-2 +Inf -inf]", NewDenseMatrix(1, 4, []float64{1e3, -2.5e-2, math.Inf(1), math.Inf(-1)})},
+		{"[\n 1 2\n\n 3 4\n]", NewDenseMatrix(2, 2, []float64{1, 2, 3, 4})},
+		{"[1 2 ... continued\n 3; 4 5 6] % comment", NewDenseMatrix(2, 3, []float64{1, 2, 3, 4, 5, 6})},
+		{"[1; 2;]", NewDenseMatrix(2, 1, []float64{1, 2})},
+		{"[]", NewDenseMatrix(0, 0, []float64{})},
+	} {
+		got, err := ParseMATLAB(test.input)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if !matrixEqual(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.input, got.data, test.want.data)
+		}
+	}
+
+	got, err := ParseMATLAB("[NaN 1]")
+	if err != nil || !math.IsNaN(got.At(0, 0)) || got.At(0, 1) != 1 {
+		t.Errorf("NaN: got %v, %v", got, err)
+	}
+
+	for _, test := range []struct {
+		input string
+		line  int
+	}{
+		{"1 2", 1},
+		{"[1 2", 1},
+		{"[1 2; 3]", 1},
+		{"[1 2\n3 4\n5]", 3},
+		{"[1 2]\nx", 2},
+		{"[1 2 3i]", 1},
+		{"[[1]]", 1},
+	} {
+		_, err := ParseMATLAB(test.input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected ParseError, got %v", test.input, err)
+		} else if perr.Line != test.line {
+			t.Errorf("%q: got error on line %d, want %d: %v", test.input, perr.Line, test.line, err)
+		}
+	}
+}
+
+func TestParseMATLABRoundTrip(t *testing.T) {
+	for _, m := range []*DenseM{
+		NewDenseMatrix(3, 3, []float64{1.0 / 3, -2, 3e-300, math.Inf(1), 0, math.Pi, -7.25, 1e20, 42}),
+		NewDenseMatrix(1, 3, []float64{1, -2, 3}),
+		NewDenseMatrix(3, 1, []float64{1, -2, 3}),
+	} {
+		for _, verb := range []string{"%v", "%#v"} {
+			s := fmt.Sprintf(verb, Formatted(m, FormatMATLAB()))
+			got, err := ParseMATLAB(s)
+			if err != nil {
+				t.Errorf("%s %q: %v", verb, s, err)
+				continue
+			}
+			if !matrixEqual(got, m) {
+				t.Errorf("%s %q: got %v, want %v", verb, s, got.data, m.data)
+			}
+		}
+	}
+}
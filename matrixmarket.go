@@ -0,0 +1,287 @@
+package lap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const mmBanner = "%%MatrixMarket"
+
+// ReadMatrixMarket reads a matrix in Matrix Market exchange format from r.
+// Files in coordinate format are returned as a *Sparse and files in array
+// format as a *DenseM. The real, integer and pattern fields are supported
+// with the general, symmetric and skew-symmetric qualifiers. Entries of a
+// pattern matrix are set to 1. Explicitly stored zeros of a coordinate
+// file are not retained. Duplicate coordinate entries are summed.
+//
+// Parse errors report the line of r on which they occurred.
+func ReadMatrixMarket(r io.Reader) (Matrix, error) {
+	p := mmParser{s: bufio.NewScanner(r)}
+	p.s.Buffer(nil, 1<<20)
+	if !p.next(false) {
+		return nil, p.errorf("missing header")
+	}
+	header := strings.Fields(strings.ToLower(p.text))
+	if len(header) != 5 || header[0] != strings.ToLower(mmBanner) || header[1] != "matrix" {
+		return nil, p.errorf("invalid header %q", p.text)
+	}
+	format, field, symmetry := header[2], header[3], header[4]
+	switch format {
+	case "coordinate", "array":
+	default:
+		return nil, p.errorf("unsupported format %q", format)
+	}
+	switch field {
+	case "real", "integer":
+	case "pattern":
+		if format == "array" {
+			return nil, p.errorf("pattern field requires coordinate format")
+		}
+	default:
+		return nil, p.errorf("unsupported field %q", field)
+	}
+	switch symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	default:
+		return nil, p.errorf("unsupported symmetry %q", symmetry)
+	}
+
+	if !p.next(true) {
+		return nil, p.errorf("missing size line")
+	}
+	nsize := 2
+	if format == "coordinate" {
+		nsize = 3
+	}
+	size, err := p.ints(nsize)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := size[0], size[1]
+	if rows < 0 || cols < 0 || (symmetry != "general" && rows != cols) {
+		return nil, p.errorf("invalid dimensions %dx%d for %s matrix", rows, cols, symmetry)
+	}
+	sign := 1.0
+	if symmetry == "skew-symmetric" {
+		sign = -1
+	}
+
+	if format == "array" {
+		if cols != 0 && rows > math.MaxInt64/8/cols {
+			return nil, p.errorf("matrix size %dx%d is too large", rows, cols)
+		}
+		// start returns the first row of column j that is stored in the file.
+		start := func(j int) int {
+			switch symmetry {
+			case "symmetric":
+				return j
+			case "skew-symmetric":
+				return j + 1
+			}
+			return 0
+		}
+		// The entries are read before the matrix is allocated so that a
+		// corrupt size line can not cause a large allocation.
+		var entries []float64
+		for j := 0; j < cols; j++ {
+			for i := start(j); i < rows; i++ {
+				if !p.next(true) {
+					return nil, p.errorf("unexpected end of file: missing entry (%d, %d)", i+1, j+1)
+				}
+				v, err := p.float(p.text, field)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, v)
+			}
+		}
+		if p.next(true) {
+			return nil, p.errorf("too many entries")
+		}
+		if err := p.s.Err(); err != nil {
+			return nil, err
+		}
+		m := NewDenseMatrix(rows, cols, nil)
+		k := 0
+		for j := 0; j < cols; j++ {
+			for i := start(j); i < rows; i++ {
+				m.data[i*m.stride+j] = entries[k]
+				if symmetry != "general" {
+					m.data[j*m.stride+i] = sign * entries[k]
+				}
+				k++
+			}
+		}
+		return m, nil
+	}
+
+	nnz := size[2]
+	if nnz < 0 {
+		return nil, p.errorf("invalid number of entries %d", nnz)
+	}
+	s := NewSparse(rows, cols)
+	nfields := 3
+	if field == "pattern" {
+		nfields = 2
+	}
+	for k := 0; k < nnz; k++ {
+		if !p.next(true) {
+			return nil, p.errorf("unexpected end of file: read %d of %d entries", k, nnz)
+		}
+		fields := strings.Fields(p.text)
+		if len(fields) != nfields {
+			return nil, p.errorf("expected %d fields, got %d", nfields, len(fields))
+		}
+		i, err := p.index(fields[0], rows)
+		if err != nil {
+			return nil, err
+		}
+		j, err := p.index(fields[1], cols)
+		if err != nil {
+			return nil, err
+		}
+		v := 1.0
+		if field != "pattern" {
+			v, err = p.float(fields[2], field)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case symmetry == "general":
+		case i < j:
+			return nil, p.errorf("entry (%d, %d) above the diagonal of %s matrix", i+1, j+1, symmetry)
+		case i == j && symmetry == "skew-symmetric":
+			return nil, p.errorf("diagonal entry (%d, %d) of skew-symmetric matrix", i+1, j+1)
+		case i > j:
+			s.Set(j, i, s.At(j, i)+sign*v)
+		}
+		s.Set(i, j, s.At(i, j)+v)
+	}
+	if p.next(true) {
+		return nil, p.errorf("too many entries")
+	}
+	return s, p.s.Err()
+}
+
+// WriteMatrixMarket writes m to w in Matrix Market exchange format with the
+// real field and general qualifier. Matrices that expose their non-zero
+// entries, such as *Sparse, *CSR and *CSC, are written in coordinate format
+// and all other matrices in array format. Values are written with the
+// precision needed to read them back exactly.
+func WriteMatrixMarket(w io.Writer, m Matrix) error {
+	bw := bufio.NewWriter(w)
+	rows, cols := m.Dims()
+	buf := make([]byte, 0, 64)
+	var nz interface {
+		DoNonZero(fn func(i, j int, v float64))
+	}
+	switch a := m.(type) {
+	case *Sparse:
+		nz = NewCSR(a)
+	case *CSR:
+		nz = a
+	case *CSC:
+		nz = a.ToCSR()
+	}
+	if nz != nil {
+		var count int
+		nz.DoNonZero(func(_, _ int, _ float64) { count++ })
+		fmt.Fprintf(bw, "%s matrix coordinate real general\n%d %d %d\n", mmBanner, rows, cols, count)
+		nz.DoNonZero(func(i, j int, v float64) {
+			buf = strconv.AppendInt(buf[:0], int64(i+1), 10)
+			buf = append(buf, ' ')
+			buf = strconv.AppendInt(buf, int64(j+1), 10)
+			buf = append(buf, ' ')
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		})
+		return bw.Flush()
+	}
+	fmt.Fprintf(bw, "%s matrix array real general\n%d %d\n", mmBanner, rows, cols)
+	for j := 0; j < cols; j++ {
+		for i := 0; i < rows; i++ {
+			buf = strconv.AppendFloat(buf[:0], m.At(i, j), 'g', -1, 64)
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
+
+// mmParser reads the lines of a Matrix Market file and keeps track of the
+// current line number for error reporting.
+type mmParser struct {
+	s    *bufio.Scanner
+	line int
+	text string
+}
+
+// next advances to the next line. If skip is true comment and blank lines
+// are skipped. next returns false at the end of input.
+func (p *mmParser) next(skip bool) bool {
+	for p.s.Scan() {
+		p.line++
+		p.text = strings.TrimSpace(p.s.Text())
+		if !skip || (p.text != "" && p.text[0] != '%') {
+			return true
+		}
+	}
+	p.text = ""
+	return false
+}
+
+func (p *mmParser) errorf(format string, args ...interface{}) error {
+	if err := p.s.Err(); err != nil {
+		return err
+	}
+	return &ParseError{Format: "matrix market", Line: p.line, Err: fmt.Errorf(format, args...)}
+}
+
+func (p *mmParser) ints(n int) ([]int, error) {
+	fields := strings.Fields(p.text)
+	if len(fields) != n {
+		return nil, p.errorf("expected %d integers, got %d fields", n, len(fields))
+	}
+	v := make([]int, n)
+	for i, f := range fields {
+		x, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, p.errorf("invalid integer %q", f)
+		}
+		v[i] = x
+	}
+	return v, nil
+}
+
+// index parses a one-based index and returns it zero-based.
+func (p *mmParser) index(s string, n int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, p.errorf("invalid index %q", s)
+	}
+	if i < 1 || i > n {
+		return 0, p.errorf("index %d out of range [1, %d]", i, n)
+	}
+	return i - 1, nil
+}
+
+func (p *mmParser) float(s, field string) (float64, error) {
+	if field == "integer" {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, p.errorf("invalid integer %q", s)
+		}
+		return float64(i), nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", s)
+	}
+	return v, nil
+}
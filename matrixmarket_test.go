@@ -0,0 +1,155 @@
+package lap
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadMatrixMarket(t *testing.T) {
+	for _, test := range []struct {
+		name, input string
+		want        Matrix
+	}{
+		{
+			name: "coordinate real general",
+			input: `%%MatrixMarket matrix coordinate real general
+% a comment
+3 2 3
+
+1 1 1.5
+3 2 -2e3
+2 1 4
+`,
+			want: NewDenseMatrix(3, 2, []float64{1.5, 0, 4, 0, 0, -2e3}),
+		},
+		{
+			name: "coordinate integer symmetric",
+			input: `%%MatrixMarket matrix coordinate integer symmetric
+3 3 3
+1 1 2
+3 1 -1
+2 2 5
+`,
+			want: NewDenseMatrix(3, 3, []float64{2, 0, -1, 0, 5, 0, -1, 0, 0}),
+		},
+		{
+			name: "coordinate pattern skew-symmetric",
+			input: `%%MATRIXMARKET Matrix Coordinate Pattern Skew-Symmetric
+2 2 1
+2 1
+`,
+			want: NewDenseMatrix(2, 2, []float64{0, -1, 1, 0}),
+		},
+		{
+			name: "array real general",
+			input: `%%MatrixMarket matrix array real general
+2 3
+1
+4
+2
+5
+3
+6
+`,
+			want: NewDenseMatrix(2, 3, []float64{1, 2, 3, 4, 5, 6}),
+		},
+		{
+			name: "array real symmetric",
+			input: `%%MatrixMarket matrix array real symmetric
+2 2
+1
+2
+3
+`,
+			want: NewDenseMatrix(2, 2, []float64{1, 2, 2, 3}),
+		},
+		{
+			name: "array integer skew-symmetric",
+			input: `%%MatrixMarket matrix array integer skew-symmetric
+3 3
+1
+2
+3
+`,
+			want: NewDenseMatrix(3, 3, []float64{0, -1, -2, 1, 0, -3, 2, 3, 0}),
+		},
+	} {
+		m, err := ReadMatrixMarket(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		isSparse := strings.Contains(test.name, "coordinate")
+		if _, ok := m.(*Sparse); ok != isSparse {
+			t.Errorf("%s: unexpected type %T", test.name, m)
+		}
+		if !matrixEqual(m, test.want) {
+			t.Errorf("%s: got\n%v\nwant\n%v", test.name, Formatted(m), Formatted(test.want))
+		}
+	}
+}
+
+func TestReadMatrixMarketErrors(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		line  int
+	}{
+		{"", 0},
+		{"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n", 1},
+		{"%%MatrixMarket matrix array pattern general\n1 1\n", 1},
+		{"%%MatrixMarket matrix coordinate real general\n% comment\n2 2\n", 3},
+		{"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n3 1 1\n", 4},
+		{"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n", 3},
+		{"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n", 3},
+		{"%%MatrixMarket matrix array real general\n1 2\n1\nx\n", 4},
+		{"%%MatrixMarket matrix array real general\n1 1\n1\n2\n", 4},
+		// Sizes that overflow or that the entries do not back up.
+		{"%%MatrixMarket matrix array real general\n3037000500 3037000500\n", 2},
+		{"%%MatrixMarket matrix array real general\n1048576 1048576\n1\n", 3},
+	} {
+		_, err := ReadMatrixMarket(strings.NewReader(test.input))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected ParseError, got %v", test.input, err)
+			continue
+		}
+		if perr.Line != test.line {
+			t.Errorf("%q: got error on line %d, want %d: %v", test.input, perr.Line, test.line, err)
+		}
+	}
+}
+
+func TestWriteMatrixMarket(t *testing.T) {
+	s := NewSparse(4, 3)
+	s.Set(0, 0, 1.0/3)
+	s.Set(3, 1, -7)
+	s.Set(2, 2, 1e-300)
+	d := NewDenseMatrix(2, 3, []float64{1, 0.1, 3, 4, 5, 6.5})
+	for _, m := range []Matrix{s, NewCSR(s), NewCSC(s), d} {
+		var buf bytes.Buffer
+		if err := WriteMatrixMarket(&buf, m); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadMatrixMarket(&buf)
+		if err != nil {
+			t.Fatalf("%T: %v", m, err)
+		}
+		if !matrixEqual(got, m) {
+			t.Errorf("%T: round trip mismatch", m)
+		}
+	}
+
+	var buf bytes.Buffer
+	WriteMatrixMarket(&buf, s)
+	want := `%%MatrixMarket matrix coordinate real general
+4 3 3
+1 1 0.3333333333333333
+3 3 1e-300
+4 2 -7
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,298 @@
+package lap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const npyMagic = "\x93NUMPY"
+
+// npyMaxHeaderSize is the largest header length accepted by ReadNPY,
+// the same limit NumPy applies by default.
+const npyMaxHeaderSize = 10000
+
+// NPYOption is a functional option for writing NPY files.
+type NPYOption func(*npyConfig)
+
+type npyConfig struct {
+	float32 bool
+	fortran bool
+}
+
+// NPYFloat32 makes WriteNPY store values as float32 instead of float64.
+// Values are rounded to the nearest float32.
+func NPYFloat32() NPYOption {
+	return func(c *npyConfig) { c.float32 = true }
+}
+
+// NPYFortranOrder makes WriteNPY store a matrix in column-major order.
+func NPYFortranOrder() NPYOption {
+	return func(c *npyConfig) { c.fortran = true }
+}
+
+// ReadNPY reads an array in NumPy NPY format, versions 1.0 and 2.0, from r.
+// One-dimensional arrays are returned as a *DenseV and two-dimensional arrays
+// as a *DenseM. Arrays may be stored in C or Fortran order with dtype float64
+// or float32 of either byte order. Other dtypes and dimensions are rejected
+// with an error.
+func ReadNPY(r io.Reader) (Matrix, error) {
+	br := bufio.NewReader(r)
+	var pre [len(npyMagic) + 2]byte
+	if _, err := io.ReadFull(br, pre[:]); err != nil {
+		return nil, fmt.Errorf("npy: reading magic string: %w", unexpectedEOF(err))
+	}
+	if string(pre[:len(npyMagic)]) != npyMagic {
+		return nil, errors.New("npy: not an NPY file")
+	}
+	major, minor := pre[len(npyMagic)], pre[len(npyMagic)+1]
+	var hlen int
+	switch major {
+	case 1:
+		var b [2]byte
+		if _, err := io.ReadFull(br, b[:]); err != nil {
+			return nil, fmt.Errorf("npy: reading header length: %w", unexpectedEOF(err))
+		}
+		hlen = int(binary.LittleEndian.Uint16(b[:]))
+	case 2:
+		var b [4]byte
+		if _, err := io.ReadFull(br, b[:]); err != nil {
+			return nil, fmt.Errorf("npy: reading header length: %w", unexpectedEOF(err))
+		}
+		hlen = int(binary.LittleEndian.Uint32(b[:]))
+	default:
+		return nil, fmt.Errorf("npy: unsupported format version %d.%d", major, minor)
+	}
+	if hlen > npyMaxHeaderSize {
+		return nil, fmt.Errorf("npy: header length %d exceeds the limit of %d bytes", hlen, npyMaxHeaderSize)
+	}
+	header := make([]byte, hlen)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("npy: reading header: %w", unexpectedEOF(err))
+	}
+	descr, fortran, shape, err := parseNPYHeader(string(header))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		order binary.ByteOrder
+		size  int
+	)
+	switch descr {
+	case "<f8", "<f4":
+		order = binary.LittleEndian
+	case ">f8", ">f4":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("npy: unsupported dtype %q: only float64 and float32 arrays are supported", descr)
+	}
+	size = int(descr[2] - '0')
+
+	var rows, cols int
+	switch len(shape) {
+	case 1:
+		rows, cols = shape[0], 1
+	case 2:
+		rows, cols = shape[0], shape[1]
+	default:
+		return nil, fmt.Errorf("npy: unsupported %d-dimensional array: only 1 and 2 dimensions are supported", len(shape))
+	}
+	if cols != 0 && rows > math.MaxInt64/8/cols {
+		return nil, fmt.Errorf("npy: shape %v is too large", shape)
+	}
+	total := rows * cols
+	// The data is read in chunks and grown as it is read so that a corrupt
+	// shape can not cause a large allocation before the data is found missing.
+	buf := make([]byte, 8*1024)
+	data := make([]float64, 0, min(total, len(buf)/size))
+	for len(data) < total {
+		chunk := min(total-len(data), len(buf)/size)
+		b := buf[:chunk*size]
+		if _, err := io.ReadFull(br, b); err != nil {
+			return nil, fmt.Errorf("npy: reading data: %w", unexpectedEOF(err))
+		}
+		for k := 0; k < chunk; k++ {
+			if size == 8 {
+				data = append(data, math.Float64frombits(order.Uint64(b[8*k:])))
+			} else {
+				data = append(data, float64(math.Float32frombits(order.Uint32(b[4*k:]))))
+			}
+		}
+	}
+	if len(shape) == 1 {
+		return NewDenseVector(rows, data), nil
+	}
+	if fortran {
+		m := NewDenseMatrix(rows, cols, nil)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				m.data[i*m.stride+j] = data[j*rows+i]
+			}
+		}
+		return m, nil
+	}
+	return NewDenseMatrix(rows, cols, data), nil
+}
+
+// WriteNPY writes m to w in NumPy NPY format as a little-endian array. Values are
+// written as float64 in C order unless the NPYFloat32 or NPYFortranOrder options
+// are given. Vectors are written as one-dimensional arrays and all other
+// matrices as two-dimensional arrays. Format version 1.0 is used unless the
+// header is too large for it, in which case version 2.0 is used.
+func WriteNPY(w io.Writer, m Matrix, opts ...NPYOption) error {
+	var cfg npyConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	rows, cols := m.Dims()
+	descr, size := "<f8", 8
+	if cfg.float32 {
+		descr, size = "<f4", 4
+	}
+	fortran := "False"
+	if cfg.fortran {
+		fortran = "True"
+	}
+	shape := fmt.Sprintf("(%d, %d)", rows, cols)
+	if _, ok := m.(Vector); ok {
+		shape = fmt.Sprintf("(%d,)", rows)
+	}
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': %s, }", descr, fortran, shape)
+
+	// Pad the header with spaces and a newline so the data is 64-byte aligned.
+	version, lenSize := byte(1), 2
+	hlen := npyHeaderLen(len(dict), lenSize)
+	if hlen > math.MaxUint16 {
+		version, lenSize = 2, 4
+		hlen = npyHeaderLen(len(dict), lenSize)
+	}
+	var pre bytes.Buffer
+	pre.WriteString(npyMagic)
+	pre.Write([]byte{version, 0})
+	var b4 [4]byte
+	binary.LittleEndian.PutUint32(b4[:], uint32(hlen))
+	pre.Write(b4[:lenSize])
+	pre.WriteString(dict)
+	pre.WriteString(strings.Repeat(" ", hlen-len(dict)-1))
+	pre.WriteByte('\n')
+
+	bw := bufio.NewWriter(w)
+	bw.Write(pre.Bytes())
+	var b [8]byte
+	put := func(v float64) {
+		if size == 8 {
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		} else {
+			binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(v)))
+		}
+		bw.Write(b[:size])
+	}
+	if cfg.fortran {
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				put(m.At(i, j))
+			}
+		}
+	} else {
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				put(m.At(i, j))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// npyHeaderLen returns the length of the padded header holding a dictionary
+// of length n when the header length is stored in lenSize bytes.
+func npyHeaderLen(n, lenSize int) int {
+	hlen := n + 1
+	if pad := (len(npyMagic) + 2 + lenSize + hlen) % 64; pad != 0 {
+		hlen += 64 - pad
+	}
+	return hlen
+}
+
+// parseNPYHeader parses the Python dictionary literal of an NPY header.
+func parseNPYHeader(h string) (descr string, fortran bool, shape []int, err error) {
+	h = strings.TrimSpace(h)
+	if !strings.HasPrefix(h, "{") || !strings.HasSuffix(h, "}") {
+		return "", false, nil, fmt.Errorf("npy: malformed header %q", h)
+	}
+	fields := map[string]string{}
+	rest := strings.TrimSpace(h[1 : len(h)-1])
+	for rest != "" {
+		if rest[0] != '\'' && rest[0] != '"' {
+			return "", false, nil, fmt.Errorf("npy: malformed header %q", h)
+		}
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			return "", false, nil, fmt.Errorf("npy: malformed header %q", h)
+		}
+		key := rest[1 : end+1]
+		rest = strings.TrimSpace(rest[end+2:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", false, nil, fmt.Errorf("npy: malformed header %q", h)
+		}
+		rest = strings.TrimSpace(rest[1:])
+		// Values end at the next comma outside of parentheses or brackets.
+		var depth, n int
+	scan:
+		for n = 0; n < len(rest); n++ {
+			switch rest[n] {
+			case '(', '[':
+				depth++
+			case ')', ']':
+				depth--
+			case ',':
+				if depth == 0 {
+					break scan
+				}
+			}
+		}
+		fields[key] = strings.TrimSpace(rest[:n])
+		if n < len(rest) {
+			n++
+		}
+		rest = strings.TrimSpace(rest[n:])
+	}
+
+	d, ok := fields["descr"]
+	if strings.HasPrefix(d, "[") {
+		return "", false, nil, fmt.Errorf("npy: unsupported structured dtype %s: only float64 and float32 arrays are supported", d)
+	}
+	if !ok || len(d) < 2 || (d[0] != '\'' && d[0] != '"') || d[len(d)-1] != d[0] {
+		return "", false, nil, fmt.Errorf("npy: missing or invalid descr in header %q", h)
+	}
+	descr = d[1 : len(d)-1]
+	switch fields["fortran_order"] {
+	case "True":
+		fortran = true
+	case "False":
+	default:
+		return "", false, nil, fmt.Errorf("npy: missing or invalid fortran_order in header %q", h)
+	}
+	s, ok := fields["shape"]
+	if !ok || !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return "", false, nil, fmt.Errorf("npy: missing or invalid shape in header %q", h)
+	}
+	shape = []int{}
+	for _, dim := range strings.Split(s[1:len(s)-1], ",") {
+		dim = strings.TrimSpace(dim)
+		if dim == "" {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSuffix(dim, "L"))
+		if err != nil || v < 0 {
+			return "", false, nil, fmt.Errorf("npy: invalid dimension %q in shape", dim)
+		}
+		shape = append(shape, v)
+	}
+	return descr, fortran, shape, nil
+}
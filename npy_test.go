@@ -0,0 +1,172 @@
+package lap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+// npyFile builds an NPY file with the given version, header dictionary and
+// payload in the way NumPy does.
+func npyFile(version byte, dict string, payload []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{version, 0})
+	lenSize := 2
+	if version > 1 {
+		lenSize = 4
+	}
+	hlen := len(dict) + 1
+	for (len(npyMagic)+2+lenSize+hlen)%64 != 0 {
+		hlen++
+	}
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(hlen))
+	buf.Write(b[:lenSize])
+	buf.WriteString(dict + strings.Repeat(" ", hlen-len(dict)-1) + "\n")
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func npyPayload(order binary.ByteOrder, size int, values ...float64) []byte {
+	b := make([]byte, size*len(values))
+	for i, v := range values {
+		if size == 8 {
+			order.PutUint64(b[8*i:], math.Float64bits(v))
+		} else {
+			order.PutUint32(b[4*i:], math.Float32bits(float32(v)))
+		}
+	}
+	return b
+}
+
+func TestReadNPY(t *testing.T) {
+	want := NewDenseMatrix(2, 3, []float64{1, 2, 3, 4.5, -5, 6})
+	for _, test := range []struct {
+		name string
+		file []byte
+		want Matrix
+	}{
+		{
+			name: "v1 <f8 C order",
+			file: npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }",
+				npyPayload(binary.LittleEndian, 8, 1, 2, 3, 4.5, -5, 6)),
+			want: want,
+		},
+		{
+			name: "v2 >f8 Fortran order",
+			file: npyFile(2, "{'descr': '>f8', 'fortran_order': True, 'shape': (2, 3), }",
+				npyPayload(binary.BigEndian, 8, 1, 4.5, 2, -5, 3, 6)),
+			want: want,
+		},
+		{
+			name: "v1 <f4 Fortran order",
+			file: npyFile(1, "{'descr': '<f4', 'fortran_order': True, 'shape': (2, 3), }",
+				npyPayload(binary.LittleEndian, 4, 1, 4.5, 2, -5, 3, 6)),
+			want: want,
+		},
+		{
+			name: "v1 >f4 vector",
+			file: npyFile(1, "{'descr': '>f4', 'fortran_order': False, 'shape': (3,), }",
+				npyPayload(binary.BigEndian, 4, 0.5, -1, 2)),
+			want: NewDenseVector(3, []float64{0.5, -1, 2}),
+		},
+		{
+			name: "reordered keys and double quotes",
+			file: npyFile(1, `{"shape": (1, 2), "fortran_order": False, "descr": "<f8"}`,
+				npyPayload(binary.LittleEndian, 8, 7, 8)),
+			want: NewDenseMatrix(1, 2, []float64{7, 8}),
+		},
+	} {
+		got, err := ReadNPY(bytes.NewReader(test.file))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		_, isVec := test.want.(*DenseV)
+		if _, ok := got.(*DenseV); ok != isVec {
+			t.Errorf("%s: unexpected type %T", test.name, got)
+		}
+		if !matrixEqual(got, test.want) {
+			t.Errorf("%s: got\n%v\nwant\n%v", test.name, Formatted(got), Formatted(test.want))
+		}
+	}
+}
+
+func TestReadNPYErrors(t *testing.T) {
+	for _, test := range []struct {
+		file []byte
+		want string
+	}{
+		{[]byte("not npy at all"), "not an NPY file"},
+		{npyFile(3, "{'descr': '<f8', 'fortran_order': False, 'shape': (1,), }", nil), "version"},
+		{npyFile(1, "{'descr': '<i8', 'fortran_order': False, 'shape': (1,), }", make([]byte, 8)), `dtype "<i8"`},
+		{npyFile(1, "{'descr': '<c16', 'fortran_order': False, 'shape': (1,), }", make([]byte, 16)), `dtype "<c16"`},
+		{npyFile(1, "{'descr': [('a', '<f8'), ('b', '<f8')], 'fortran_order': False, 'shape': (1,), }", make([]byte, 16)), "structured dtype"},
+		{npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (1, 1, 1), }", make([]byte, 8)), "3-dimensional"},
+		{npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (), }", make([]byte, 8)), "0-dimensional"},
+		{npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2,), }", make([]byte, 8)), "unexpected EOF"},
+		{npyFile(1, "{'descr': '<f8', 'shape': (2,), }", make([]byte, 16)), "fortran_order"},
+		{npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (4294967296, 4294967296), }", nil), "too large"},
+		{npyFile(1, "{'descr': '<f4', 'fortran_order': False, 'shape': (1073741824, 1073741824), }", nil), "too large"},
+		{npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (1048576, 1048576), }", make([]byte, 8)), "unexpected EOF"},
+		{[]byte(npyMagic + "\x02\x00\xff\xff\xff\xff"), "header length 4294967295"},
+	} {
+		_, err := ReadNPY(bytes.NewReader(test.file))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("got error %v, want error containing %q", err, test.want)
+		}
+	}
+}
+
+func TestWriteNPY(t *testing.T) {
+	m := NewDenseMatrix(3, 2, []float64{1, math.Pi, -2, math.Inf(1), 0.1, 1e-3})
+	v := NewDenseVector(4, []float64{1, 2, 3, 4})
+	for _, test := range []struct {
+		m    Matrix
+		opts []NPYOption
+		tol  float64
+	}{
+		{m: m},
+		{m: m, opts: []NPYOption{NPYFortranOrder()}},
+		{m: m, opts: []NPYOption{NPYFloat32(), NPYFortranOrder()}, tol: 1e-6},
+		{m: v},
+		{m: v, opts: []NPYOption{NPYFloat32()}},
+	} {
+		var buf bytes.Buffer
+		if err := WriteNPY(&buf, test.m, test.opts...); err != nil {
+			t.Fatal(err)
+		}
+		b := buf.Bytes()
+		hlen := int(binary.LittleEndian.Uint16(b[8:]))
+		if b[6] != 1 || (10+hlen)%64 != 0 || b[10+hlen-1] != '\n' {
+			t.Errorf("bad header %q", b[:10+hlen])
+		}
+		got, err := ReadNPY(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := test.m.(*DenseV); ok {
+			if _, ok := got.(*DenseV); !ok {
+				t.Errorf("vector read back as %T", got)
+			}
+		}
+		r, c := test.m.Dims()
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				want := test.m.At(i, j)
+				if g := got.At(i, j); g != want && !almostEqual(g, want, test.tol*math.Abs(want)) {
+					t.Errorf("element (%d, %d): got %v, want %v", i, j, g, want)
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	WriteNPY(&buf, NewDenseMatrix(2, 3, nil))
+	const header = "\x93NUMPY\x01\x00v\x00{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }"
+	if !strings.HasPrefix(buf.String(), header) || buf.Len() != 128+6*8 {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,345 @@
+package lap
+
+import "math"
+
+var (
+	_ Preconditioner = &Jacobi{}
+	_ Preconditioner = &SSOR{}
+	_ Preconditioner = &ILU0{}
+	_ Preconditioner = &IC0{}
+)
+
+// Jacobi is the diagonal preconditioner M = diag(A).
+type Jacobi struct {
+	inv []float64
+}
+
+// NewJacobi returns the diagonal preconditioner of the square matrix A.
+// NewJacobi returns ErrSingular if a diagonal element of A is zero and
+// panics with ErrDim if A is not square.
+func NewJacobi(A Matrix) (*Jacobi, error) {
+	n := squareDim(A)
+	inv := make([]float64, n)
+	for i := range inv {
+		d := A.At(i, i)
+		if d == 0 {
+			return nil, ErrSingular
+		}
+		inv[i] = 1 / d
+	}
+	return &Jacobi{inv: inv}, nil
+}
+
+// Apply stores M^-1 * r in dst. If dst is not initialized it is allocated automatically.
+func (p *Jacobi) Apply(dst *DenseV, r Vector) {
+	loadResidual(dst, r, len(p.inv))
+	for i, v := range p.inv {
+		dst.SetVec(i, v*dst.AtVec(i))
+	}
+}
+
+// SSOR is the symmetric successive over-relaxation preconditioner
+//
+//	M = 1/(ω(2-ω)) * (D + ωL) * D^-1 * (D + ωU)
+//
+// where D, L and U are the diagonal, strictly lower and strictly upper
+// triangular parts of A. For ω = 1 it is the symmetric Gauss-Seidel
+// preconditioner.
+type SSOR struct {
+	a     *CSR
+	diag  []float64
+	omega float64
+}
+
+// NewSSOR returns the SSOR preconditioner of the square matrix A with relaxation
+// factor omega. NewSSOR returns ErrSingular if a diagonal element of A is zero.
+// NewSSOR panics with ErrDim if A is not square and panics if omega is not in (0, 2).
+func NewSSOR(A Matrix, omega float64) (*SSOR, error) {
+	if !(omega > 0 && omega < 2) {
+		panic("relaxation factor must be in (0, 2)")
+	}
+	squareDim(A)
+	a := toCSR(A)
+	diag := make([]float64, a.nmajor)
+	for i := range diag {
+		diag[i] = a.at(i, i)
+		if diag[i] == 0 {
+			return nil, ErrSingular
+		}
+	}
+	return &SSOR{a: a, diag: diag, omega: omega}, nil
+}
+
+// Apply stores M^-1 * r in dst. If dst is not initialized it is allocated automatically.
+func (p *SSOR) Apply(dst *DenseV, r Vector) {
+	a, w := p.a, p.omega
+	loadResidual(dst, r, a.nmajor)
+	// Solve (D + ωL) y = r.
+	for i := 0; i < a.nmajor; i++ {
+		sum := dst.AtVec(i)
+		for n := a.indptr[i]; n < a.indptr[i+1] && a.ind[n] < i; n++ {
+			sum -= w * a.data[n] * dst.AtVec(a.ind[n])
+		}
+		dst.SetVec(i, sum/p.diag[i])
+	}
+	// Solve (D + ωU) x = D y and scale.
+	for i := a.nmajor - 1; i >= 0; i-- {
+		sum := p.diag[i] * dst.AtVec(i)
+		for n := a.indptr[i+1] - 1; n >= a.indptr[i] && a.ind[n] > i; n-- {
+			sum -= w * a.data[n] * dst.AtVec(a.ind[n])
+		}
+		dst.SetVec(i, sum/p.diag[i])
+	}
+	scale := w * (2 - w)
+	for i := 0; i < a.nmajor; i++ {
+		dst.SetVec(i, scale*dst.AtVec(i))
+	}
+}
+
+// ILU0 is the incomplete LU factorization preconditioner M = L*U where L is
+// unit lower triangular, U is upper triangular and both have the sparsity
+// pattern of A, with no fill-in.
+type ILU0 struct {
+	// lu holds the strictly lower part of L and the upper part of U
+	// in the sparsity pattern of A.
+	lu   *CSR
+	diag []int
+}
+
+// NewILU0 computes the ILU(0) factorization of the square matrix A.
+// NewILU0 returns ErrSingular if a zero pivot is encountered or A has a zero
+// on the diagonal. NewILU0 panics with ErrDim if A is not square.
+func NewILU0(A Matrix) (*ILU0, error) {
+	squareDim(A)
+	a := toCSR(A)
+	n := a.nmajor
+	lu := &CSR{compressed: compressed{
+		nmajor: n,
+		nminor: n,
+		indptr: a.indptr,
+		ind:    a.ind,
+		data:   append([]float64(nil), a.data...),
+	}}
+	diag, err := diagIndices(&lu.compressed)
+	if err != nil {
+		return nil, err
+	}
+	// pos maps the column index to the storage position in the current row.
+	pos := make([]int, n)
+	for i := range pos {
+		pos[i] = -1
+	}
+	for i := 0; i < n; i++ {
+		start, end := lu.indptr[i], lu.indptr[i+1]
+		for k := start; k < end; k++ {
+			pos[lu.ind[k]] = k
+		}
+		for k := start; k < diag[i]; k++ {
+			col := lu.ind[k]
+			pivot := lu.data[diag[col]]
+			if pivot == 0 {
+				return nil, ErrSingular
+			}
+			lu.data[k] /= pivot
+			for m := diag[col] + 1; m < lu.indptr[col+1]; m++ {
+				if p := pos[lu.ind[m]]; p >= 0 {
+					lu.data[p] -= lu.data[k] * lu.data[m]
+				}
+			}
+		}
+		if lu.data[diag[i]] == 0 {
+			return nil, ErrSingular
+		}
+		for k := start; k < end; k++ {
+			pos[lu.ind[k]] = -1
+		}
+	}
+	return &ILU0{lu: lu, diag: diag}, nil
+}
+
+// Apply stores M^-1 * r in dst. If dst is not initialized it is allocated automatically.
+func (p *ILU0) Apply(dst *DenseV, r Vector) {
+	lu := p.lu
+	loadResidual(dst, r, lu.nmajor)
+	for i := 0; i < lu.nmajor; i++ {
+		sum := dst.AtVec(i)
+		for n := lu.indptr[i]; n < p.diag[i]; n++ {
+			sum -= lu.data[n] * dst.AtVec(lu.ind[n])
+		}
+		dst.SetVec(i, sum)
+	}
+	for i := lu.nmajor - 1; i >= 0; i-- {
+		sum := dst.AtVec(i)
+		for n := p.diag[i] + 1; n < lu.indptr[i+1]; n++ {
+			sum -= lu.data[n] * dst.AtVec(lu.ind[n])
+		}
+		dst.SetVec(i, sum/lu.data[p.diag[i]])
+	}
+}
+
+// IC0 is the incomplete Cholesky factorization preconditioner M = L*L^T where
+// L is lower triangular with the sparsity pattern of the lower triangle of A.
+type IC0 struct {
+	l *CSR
+}
+
+// NewIC0 computes the IC(0) factorization of the symmetric positive definite
+// matrix A. Only the lower triangle of A is referenced.
+// NewIC0 returns ErrNotPD if the factorization breaks down, which may happen
+// even for positive definite matrices. NewIC0 panics with ErrDim if A is not square.
+func NewIC0(A Matrix) (*IC0, error) {
+	squareDim(A)
+	a := toCSR(A)
+	n := a.nmajor
+	var I, J []int
+	var V []float64
+	for i := 0; i < n; i++ {
+		for k := a.indptr[i]; k < a.indptr[i+1] && a.ind[k] <= i; k++ {
+			I = append(I, i)
+			J = append(J, a.ind[k])
+			V = append(V, a.data[k])
+		}
+	}
+	l := &CSR{compressed: newCompressed(n, n, I, J, V)}
+	for i := 0; i < n; i++ {
+		start, end := l.indptr[i], l.indptr[i+1]
+		if start == end || l.ind[end-1] != i {
+			return nil, ErrNotPD
+		}
+		// Entries are computed left to right so that l[i][j] for j < k
+		// is final when l[i][k] is computed.
+		for k := start; k < end-1; k++ {
+			col := l.ind[k]
+			s := l.data[k] - sparseDot(&l.compressed, i, col, col)
+			l.data[k] = s / l.data[l.indptr[col+1]-1]
+		}
+		d := l.data[end-1] - sparseDot(&l.compressed, i, i, i)
+		if !(d > 0) {
+			return nil, ErrNotPD
+		}
+		l.data[end-1] = math.Sqrt(d)
+	}
+	return &IC0{l: l}, nil
+}
+
+// Apply stores M^-1 * r in dst. If dst is not initialized it is allocated automatically.
+func (p *IC0) Apply(dst *DenseV, r Vector) {
+	l := p.l
+	loadResidual(dst, r, l.nmajor)
+	// Solve L y = r.
+	for i := 0; i < l.nmajor; i++ {
+		last := l.indptr[i+1] - 1
+		sum := dst.AtVec(i)
+		for n := l.indptr[i]; n < last; n++ {
+			sum -= l.data[n] * dst.AtVec(l.ind[n])
+		}
+		dst.SetVec(i, sum/l.data[last])
+	}
+	// Solve L^T x = y by columns of L^T.
+	for i := l.nmajor - 1; i >= 0; i-- {
+		last := l.indptr[i+1] - 1
+		xi := dst.AtVec(i) / l.data[last]
+		dst.SetVec(i, xi)
+		for n := l.indptr[i]; n < last; n++ {
+			j := l.ind[n]
+			dst.SetVec(j, dst.AtVec(j)-l.data[n]*xi)
+		}
+	}
+}
+
+// sparseDot returns the sum of a[i][j]*a[k][j] over the common column indices
+// j < limit of rows i and k.
+func sparseDot(a *compressed, i, k, limit int) (sum float64) {
+	p, pend := a.indptr[i], a.indptr[i+1]
+	q, qend := a.indptr[k], a.indptr[k+1]
+	for p < pend && q < qend {
+		pj, qj := a.ind[p], a.ind[q]
+		if pj >= limit || qj >= limit {
+			break
+		}
+		switch {
+		case pj < qj:
+			p++
+		case pj > qj:
+			q++
+		default:
+			sum += a.data[p] * a.data[q]
+			p++
+			q++
+		}
+	}
+	return sum
+}
+
+// diagIndices returns the storage position of the diagonal element of each
+// major index. ErrSingular is returned if a diagonal element is not stored.
+func diagIndices(a *compressed) ([]int, error) {
+	diag := make([]int, a.nmajor)
+	for k := range diag {
+		start, end := a.indptr[k], a.indptr[k+1]
+		diag[k] = -1
+		for n := start; n < end; n++ {
+			if a.ind[n] == k {
+				diag[k] = n
+				break
+			}
+		}
+		if diag[k] < 0 {
+			return nil, ErrSingular
+		}
+	}
+	return diag, nil
+}
+
+// toCSR returns A in compressed sparse row form. A is returned as is if it
+// is already a *CSR.
+func toCSR(A Matrix) *CSR {
+	switch a := A.(type) {
+	case *CSR:
+		return a
+	case *CSC:
+		return a.ToCSR()
+	case *Sparse:
+		return NewCSR(a)
+	}
+	r, c := A.Dims()
+	var I, J []int
+	var V []float64
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if v := A.At(i, j); v != 0 {
+				I = append(I, i)
+				J = append(J, j)
+				V = append(V, v)
+			}
+		}
+	}
+	return &CSR{compressed: newCompressed(r, c, I, J, V)}
+}
+
+// squareDim returns the dimension of A and panics with ErrDim if A is not square.
+func squareDim(A Matrix) int {
+	r, c := A.Dims()
+	if r != c {
+		panic(ErrDim)
+	}
+	return r
+}
+
+// loadResidual copies r into dst, allocating dst if it is not initialized.
+func loadResidual(dst *DenseV, r Vector, n int) {
+	if r.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if dst != r {
+		for i := 0; i < n; i++ {
+			dst.SetVec(i, r.AtVec(i))
+		}
+	}
+}
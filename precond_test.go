@@ -0,0 +1,171 @@
+package lap
+
+import (
+	"errors"
+	"testing"
+)
+
+// laplacian2D returns the n²xn² five-point Laplacian on an nxn grid.
+func laplacian2D(n int) *Sparse {
+	s := NewSparse(n*n, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			k := i*n + j
+			s.Set(k, k, 4)
+			if i > 0 {
+				s.Set(k, k-n, -1)
+				s.Set(k-n, k, -1)
+			}
+			if j > 0 {
+				s.Set(k, k-1, -1)
+				s.Set(k-1, k, -1)
+			}
+		}
+	}
+	return s
+}
+
+func TestPreconditionerExact(t *testing.T) {
+	// For a tridiagonal matrix ILU(0) and IC(0) have no dropped fill-in
+	// and are exact factorizations.
+	const n = 12
+	A := laplacian1D(n)
+	ilu, err := NewILU0(A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ic, err := NewIC0(NewCSC(A))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewDenseVector(n, nil)
+	want.DoSetVec(func(i int, _ float64) float64 { return float64(i) - 4 })
+	var b DenseV
+	A.MulVecTo(&b, want)
+	for _, M := range []Preconditioner{ilu, ic} {
+		var x DenseV
+		M.Apply(&x, &b)
+		if !vectorEqualTol(&x, want, 1e-12) {
+			t.Errorf("%T: got %v, want %v", M, x.data, want.data)
+		}
+	}
+
+	jac, err := NewJacobi(A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var x DenseV
+	jac.Apply(&x, &b)
+	for i := 0; i < n; i++ {
+		if !almostEqual(x.AtVec(i), b.AtVec(i)/2, 1e-15) {
+			t.Fatalf("Jacobi: got %v", x.data)
+		}
+	}
+}
+
+func TestSSOR(t *testing.T) {
+	const omega = 1.3
+	A := NewDenseMatrix(3, 3, []float64{
+		4, -1, 2,
+		1, 5, -1,
+		0, 2, 6,
+	})
+	// Build M from its definition and check M * M^-1 r = r.
+	D, L, U := NewDenseMatrix(3, 3, nil), NewDenseMatrix(3, 3, nil), NewDenseMatrix(3, 3, nil)
+	Dinv := NewDenseMatrix(3, 3, nil)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			v := A.At(i, j)
+			switch {
+			case i == j:
+				D.Set(i, j, v)
+				L.Set(i, j, v)
+				U.Set(i, j, v)
+				Dinv.Set(i, j, 1/v)
+			case i > j:
+				L.Set(i, j, omega*v)
+			default:
+				U.Set(i, j, omega*v)
+			}
+		}
+	}
+	var tmp, M DenseM
+	tmp.Mul(L, Dinv)
+	M.Mul(&tmp, U)
+	M.DoSet(func(_, _ int, v float64) float64 { return v / (omega * (2 - omega)) })
+
+	p, err := NewSSOR(A, omega)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewDenseVector(3, []float64{1, -2, 3})
+	var z, got DenseV
+	p.Apply(&z, r)
+	got.MulVec(&M, &z)
+	if !vectorEqualTol(&got, r, 1e-12) {
+		t.Errorf("got %v, want %v", got.data, r.data)
+	}
+}
+
+func TestPreconditionedSolvers(t *testing.T) {
+	const grid = 12
+	A := laplacian2D(grid)
+	n, _ := A.Dims()
+	want := NewDenseVector(n, nil)
+	want.DoSetVec(func(i int, _ float64) float64 { return float64(i%9) - 4 })
+	var b DenseV
+	A.MulVecTo(&b, want)
+
+	var x DenseV
+	base, err := CG(&x, A, &b, IterSettings{Tolerance: 1e-10})
+	if err != nil || !base.Converged {
+		t.Fatalf("unpreconditioned CG failed: %+v, %v", base, err)
+	}
+	jac, _ := NewJacobi(A)
+	ssor, _ := NewSSOR(A, 1.5)
+	ilu, _ := NewILU0(A)
+	ic, _ := NewIC0(A)
+	for _, M := range []Preconditioner{jac, ssor, ilu, ic} {
+		for name, solve := range map[string]func(*DenseV, LinearOperator, Vector, IterSettings) (IterResult, error){
+			"CG": CG, "GMRES": GMRES, "BiCGSTAB": BiCGSTAB,
+		} {
+			if name == "CG" && M == Preconditioner(ilu) {
+				continue
+			}
+			var x DenseV
+			result, err := solve(&x, NewCSR(A), &b, IterSettings{Tolerance: 1e-10, Preconditioner: M})
+			if err != nil {
+				t.Fatalf("%s %T: %v", name, M, err)
+			}
+			if !result.Converged {
+				t.Errorf("%s %T: did not converge, residual %g", name, M, result.Residual)
+			}
+			if !vectorEqualTol(&x, want, 1e-7) {
+				t.Errorf("%s %T: bad solution", name, M)
+			}
+			if name == "CG" && M != Preconditioner(jac) && result.Iterations >= base.Iterations {
+				t.Errorf("%T: preconditioned CG took %d iterations, unpreconditioned %d", M, result.Iterations, base.Iterations)
+			}
+		}
+	}
+}
+
+func TestPreconditionerErrors(t *testing.T) {
+	zeroDiag := NewSparse(2, 2)
+	zeroDiag.Set(0, 1, 1)
+	zeroDiag.Set(1, 0, 1)
+	zeroDiag.Set(1, 1, 1)
+	if _, err := NewJacobi(zeroDiag); !errors.Is(err, ErrSingular) {
+		t.Errorf("Jacobi: expected ErrSingular, got %v", err)
+	}
+	if _, err := NewSSOR(zeroDiag, 1); !errors.Is(err, ErrSingular) {
+		t.Errorf("SSOR: expected ErrSingular, got %v", err)
+	}
+	if _, err := NewILU0(zeroDiag); !errors.Is(err, ErrSingular) {
+		t.Errorf("ILU0: expected ErrSingular, got %v", err)
+	}
+	indefinite := NewDenseMatrix(2, 2, []float64{1, 2, 2, 1})
+	if _, err := NewIC0(indefinite); !errors.Is(err, ErrNotPD) {
+		t.Errorf("IC0: expected ErrNotPD, got %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package lap
+
+import "math"
+
+// QR is a type for creating and using the QR factorization of an mxn matrix
+// with m >= n
+//
+//	A = Q * R
+//
+// where Q is an mxm orthogonal matrix and R is an mxn upper triangular matrix.
+// The factorization is computed with Householder reflections and Q is stored
+// implicitly as the product of the reflections.
+type QR struct {
+	// qr stores R on and above the diagonal and the Householder vectors
+	// below the diagonal. The first element of each vector is an implicit 1.
+	qr  DenseM
+	tau []float64
+}
+
+// Factorize computes the QR factorization of the mxn matrix A and stores the
+// result in the receiver. Factorize panics with ErrDim if A has fewer rows than columns.
+func (qr *QR) Factorize(A Matrix) {
+	m, n := A.Dims()
+	if m < n {
+		panic(ErrDim)
+	}
+	if qr.qr.r != m || qr.qr.c != n {
+		qr.qr = *NewDenseMatrix(m, n, nil)
+	}
+	qr.qr.Copy(A)
+	if cap(qr.tau) < n {
+		qr.tau = make([]float64, n)
+	}
+	qr.tau = qr.tau[:n]
+	qrFactorize(qr.qr.data, qr.qr.stride, m, n, qr.tau)
+}
+
+// applyQT overwrites x with Q^T*x.
+func (qr *QR) applyQT(x *DenseV) {
+	_, n := qr.qr.Dims()
+	for k := 0; k < n; k++ {
+		qr.reflect(x, k)
+	}
+}
+
+// applyQ overwrites x with Q*x.
+func (qr *QR) applyQ(x *DenseV) {
+	_, n := qr.qr.Dims()
+	for k := n - 1; k >= 0; k-- {
+		qr.reflect(x, k)
+	}
+}
+
+// reflect applies the kth Householder reflection to x.
+func (qr *QR) reflect(x *DenseV, k int) {
+	qrReflect(qr.qr.data, qr.qr.stride, qr.qr.r, qr.tau, k, x.data, x.incMinusOne+1)
+}
+
+// QTo stores the mxm orthogonal matrix Q in dst. If dst is not
+// initialized it is allocated automatically.
+func (qr *QR) QTo(dst *DenseM) {
+	m, _ := qr.qr.Dims()
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(m, m, nil)
+	}
+	if r, c := dst.Dims(); r != m || c != m {
+		panic(ErrDim)
+	}
+	dst.Copy(Eye(m))
+	for j := 0; j < m; j++ {
+		qr.applyQ(dst.ColView(j))
+	}
+}
+
+// RTo stores the mxn upper triangular matrix R in dst. If dst is not
+// initialized it is allocated automatically.
+func (qr *QR) RTo(dst *DenseM) {
+	m, n := qr.qr.Dims()
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(m, n, nil)
+	}
+	if r, c := dst.Dims(); r != m || c != n {
+		panic(ErrDim)
+	}
+	dst.DoSet(func(i, j int, _ float64) float64 {
+		if i <= j {
+			return qr.qr.data[i*qr.qr.stride+j]
+		}
+		return 0
+	})
+}
+
+// isSingular reports whether R has an exactly zero diagonal element,
+// in which case A does not have full column rank.
+func (qr *QR) isSingular() bool {
+	return hasZeroDiag(qr.qr.data, qr.qr.stride, qr.qr.c)
+}
+
+// SolveLeastSquares finds the x that minimizes the 2-norm of A*x - b using
+// the factorization of A and stores it in dst. If dst is not initialized it
+// is allocated automatically.
+// SolveLeastSquares returns ErrSingular if A does not have full column rank.
+func (qr *QR) SolveLeastSquares(dst *DenseV, b Vector) error {
+	m, n := qr.qr.Dims()
+	if b.Len() != m {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if qr.isSingular() {
+		return ErrSingular
+	}
+	work := NewDenseVector(m, nil)
+	work.CopyVec(b)
+	qr.applyQT(work)
+	// Back substitute R[:n,:n]*x = (Q^T*b)[:n].
+	qrSolveR(qr.qr.data, qr.qr.stride, n, work.data, false)
+	dst.CopyVec(NewDenseVector(n, work.data[:n]))
+	return nil
+}
+
+// solveMinNorm finds the minimum norm x that satisfies A*x = b where
+// the receiver holds the factorization of A^T, and stores it in dst.
+func (qr *QR) solveMinNorm(dst *DenseV, b Vector) error {
+	m, n := qr.qr.Dims()
+	if b.Len() != n {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(m, nil)
+	}
+	if dst.Len() != m {
+		panic(ErrDim)
+	}
+	if qr.isSingular() {
+		return ErrSingular
+	}
+	// A = R^T*Q^T, so solve R[:n,:n]^T*y = b and set x = Q*[y; 0].
+	work := NewDenseVector(m, nil)
+	a := qr.qr.data
+	stride := qr.qr.stride
+	for i := 0; i < n; i++ {
+		sum := b.AtVec(i)
+		for j := 0; j < i; j++ {
+			sum -= a[j*stride+i] * work.data[j]
+		}
+		work.data[i] = sum / a[i*stride+i]
+	}
+	qr.applyQ(work)
+	dst.CopyVec(work)
+	return nil
+}
+
+// qrFactorize overwrites the mxn matrix a, stored row major with the given
+// stride, with its QR factorization. R is stored on and above the diagonal and
+// the Householder vectors below it, with their scale factors stored in tau.
+// qrFactorize is shared by QR and QROf.
+func qrFactorize[T Float](a []T, stride, m, n int, tau []T) {
+	for k := 0; k < n; k++ {
+		kk := k*stride + k
+		var xnorm T
+		for i := k + 1; i < m; i++ {
+			xnorm = hypotOf(xnorm, a[i*stride+k])
+		}
+		if xnorm == 0 {
+			// Nothing to annihilate below the diagonal.
+			tau[k] = 0
+			continue
+		}
+		alpha := a[kk]
+		beta := -T(math.Copysign(float64(hypotOf(alpha, xnorm)), float64(alpha)))
+		tau[k] = (beta - alpha) / beta
+		scal := 1 / (alpha - beta)
+		for i := k + 1; i < m; i++ {
+			a[i*stride+k] *= scal
+		}
+		a[kk] = beta
+		// Apply reflection H = I - tau*v*v^T to the trailing columns.
+		for j := k + 1; j < n; j++ {
+			s := a[k*stride+j]
+			for i := k + 1; i < m; i++ {
+				s += a[i*stride+k] * a[i*stride+j]
+			}
+			s *= tau[k]
+			a[k*stride+j] -= s
+			for i := k + 1; i < m; i++ {
+				a[i*stride+j] -= s * a[i*stride+k]
+			}
+		}
+	}
+}
+
+// qrReflect applies the kth Householder reflection of the factorization of an
+// mxn matrix computed by qrFactorize to the vector x, whose elements are inc apart.
+func qrReflect[T Float](a []T, stride, m int, tau []T, k int, x []T, inc int) {
+	t := tau[k]
+	if t == 0 {
+		return
+	}
+	s := x[k*inc]
+	for i := k + 1; i < m; i++ {
+		s += a[i*stride+k] * x[i*inc]
+	}
+	s *= t
+	x[k*inc] -= s
+	for i := k + 1; i < m; i++ {
+		x[i*inc] -= s * a[i*stride+k]
+	}
+}
+
+// qrSolveR overwrites the first n elements of x with the solution of
+// R[:n,:n]*y = x[:n], or R[:n,:n]^T*y = x[:n] if trans is true.
+func qrSolveR[T Float](a []T, stride, n int, x []T, trans bool) {
+	if trans {
+		for i := 0; i < n; i++ {
+			sum := x[i]
+			for j := 0; j < i; j++ {
+				sum -= a[j*stride+i] * x[j]
+			}
+			x[i] = sum / a[i*stride+i]
+		}
+		return
+	}
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		for j := i + 1; j < n; j++ {
+			sum -= a[i*stride+j] * x[j]
+		}
+		x[i] = sum / a[i*stride+i]
+	}
+}
+
+// rcond estimates the reciprocal of the 1-norm condition number of the nxn
+// upper triangular factor R using Hager's method. The 2-norm condition
+// numbers of R and of the factorized matrix are equal, and the 1-norm and
+// 2-norm condition numbers of R differ by at most a factor of n.
+func (qr *QR) rcond() float64 {
+	_, n := qr.qr.Dims()
+	return qrRcond(qr.qr.data, qr.qr.stride, n)
+}
+
+// qrRcond estimates the reciprocal of the 1-norm condition number of the
+// leading nxn upper triangle R of the factorization computed by qrFactorize.
+func qrRcond[T Float](a []T, stride, n int) float64 {
+	if n == 0 {
+		return 1
+	}
+	if hasZeroDiag(a, stride, n) {
+		return 0
+	}
+	var rnorm float64
+	for j := 0; j < n; j++ {
+		var sum float64
+		for i := 0; i <= j; i++ {
+			sum += math.Abs(float64(a[i*stride+j]))
+		}
+		rnorm = math.Max(rnorm, sum)
+	}
+	return 1 / (rnorm * invNorm1(n, func(x []T, trans bool) { qrSolveR(a, stride, n, x, trans) }))
+}
+
+// QROf is the generic counterpart of QR for matrices with elements of type T.
+// It shares its implementation with QR. Unlike QR, SolveLeastSquares of QROf
+// returns a ConditionError if the reciprocal condition number of R is below
+// the machine epsilon of T.
+type QROf[T Float] struct {
+	qr  DenseMOf[T]
+	tau []T
+}
+
+// Factorize computes the QR factorization of the mxn matrix A and stores the
+// result in the receiver. Factorize panics with ErrDim if A has fewer rows than columns.
+func (qr *QROf[T]) Factorize(A MatrixOf[T]) {
+	m, n := A.Dims()
+	if m < n {
+		panic(ErrDim)
+	}
+	if qr.qr.r != m || qr.qr.c != n {
+		qr.qr = *NewDenseMatrixOf[T](m, n, nil)
+	}
+	qr.qr.Copy(A)
+	if cap(qr.tau) < n {
+		qr.tau = make([]T, n)
+	}
+	qr.tau = qr.tau[:n]
+	qrFactorize(qr.qr.data, qr.qr.stride, m, n, qr.tau)
+}
+
+// QTo stores the mxm orthogonal matrix Q in dst. If dst is not
+// initialized it is allocated automatically.
+func (qr *QROf[T]) QTo(dst *DenseMOf[T]) {
+	m, n := qr.qr.Dims()
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](m, m, nil)
+	}
+	if r, c := dst.Dims(); r != m || c != m {
+		panic(ErrDim)
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			var v T
+			if i == j {
+				v = 1
+			}
+			dst.data[i*dst.stride+j] = v
+		}
+	}
+	for j := 0; j < m; j++ {
+		for k := n - 1; k >= 0; k-- {
+			qrReflect(qr.qr.data, qr.qr.stride, m, qr.tau, k, dst.data[j:], dst.stride)
+		}
+	}
+}
+
+// RTo stores the mxn upper triangular matrix R in dst. If dst is not
+// initialized it is allocated automatically.
+func (qr *QROf[T]) RTo(dst *DenseMOf[T]) {
+	m, n := qr.qr.Dims()
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](m, n, nil)
+	}
+	if r, c := dst.Dims(); r != m || c != n {
+		panic(ErrDim)
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			var v T
+			if i <= j {
+				v = qr.qr.data[i*qr.qr.stride+j]
+			}
+			dst.data[i*dst.stride+j] = v
+		}
+	}
+}
+
+// SolveLeastSquares finds the x that minimizes the 2-norm of A*x - b using
+// the factorization of A and stores it in dst. If dst is not initialized it
+// is allocated automatically.
+// SolveLeastSquares returns a ConditionError if A does not have full column
+// rank or is nearly rank deficient.
+func (qr *QROf[T]) SolveLeastSquares(dst *DenseVOf[T], b VectorOf[T]) error {
+	m, n := qr.qr.Dims()
+	if b.Len() != m {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVectorOf[T](n, nil)
+	}
+	if dst.Len() != n {
+		panic(ErrDim)
+	}
+	if rcond := qrRcond(qr.qr.data, qr.qr.stride, n); rcond < epsilonOf[T]() || math.IsNaN(rcond) {
+		return ConditionError(rcond)
+	}
+	work := make([]T, m)
+	for i := range work {
+		work[i] = b.AtVec(i)
+	}
+	for k := 0; k < n; k++ {
+		qrReflect(qr.qr.data, qr.qr.stride, m, qr.tau, k, work, 1)
+	}
+	qrSolveR(qr.qr.data, qr.qr.stride, n, work, false)
+	for i := 0; i < n; i++ {
+		dst.SetVec(i, work[i])
+	}
+	return nil
+}
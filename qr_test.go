@@ -0,0 +1,70 @@
+package lap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestQRFactorize(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{{3, 3}, {5, 3}, {7, 1}, {4, 4}} {
+		m, n := dims[0], dims[1]
+		A := NewDenseMatrix(m, n, randomSlice(rng, m*n))
+		var qr QR
+		qr.Factorize(A)
+		var Q, R, QR, QTQ DenseM
+		qr.QTo(&Q)
+		qr.RTo(&R)
+		QR.Mul(&Q, &R)
+		if !matrixEqualTol(&QR, A, 1e-12) {
+			t.Errorf("%dx%d: Q*R != A", m, n)
+		}
+		QTQ.Mul(T(&Q), &Q)
+		if !matrixEqualTol(&QTQ, Eye(m), 1e-12) {
+			t.Errorf("%dx%d: Q is not orthogonal", m, n)
+		}
+		for i := 0; i < m; i++ {
+			for j := 0; j < i && j < n; j++ {
+				if R.At(i, j) != 0 {
+					t.Errorf("%dx%d: R not upper triangular", m, n)
+				}
+			}
+		}
+	}
+}
+
+func TestQRSolveLeastSquares(t *testing.T) {
+	// Fit a line y = 2x + 1 through noiseless points.
+	A := NewDenseMatrix(4, 2, []float64{
+		0, 1,
+		1, 1,
+		2, 1,
+		3, 1,
+	})
+	b := NewDenseVector(4, []float64{1, 3, 5, 7})
+	var qr QR
+	qr.Factorize(A)
+	var x DenseV
+	err := qr.SolveLeastSquares(&x, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !vectorEqualTol(&x, NewDenseVector(2, []float64{2, 1}), 1e-12) {
+		t.Errorf("bad least squares solution %v", x.data)
+	}
+
+	// Residual of an inconsistent system must be orthogonal to the columns of A.
+	b.SetVec(2, 6)
+	err = qr.SolveLeastSquares(&x, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resid DenseV
+	resid.MulVec(A, &x)
+	resid.SubVec(&resid, b)
+	var ATr DenseV
+	ATr.MulVec(T(A), &resid)
+	if !vectorEqualTol(&ATr, NewDenseVector(2, nil), 1e-12) {
+		t.Errorf("residual not orthogonal to range of A: %v", ATr.data)
+	}
+}
@@ -0,0 +1,123 @@
+package lap
+
+import "math"
+
+// Solve solves the linear system A*X = B and stores the result in the receiver.
+// The method is chosen from the shape and structure of the mxn matrix A:
+//
+//   - m == n and A symmetric positive definite: Cholesky factorization.
+//   - m == n otherwise: LU factorization with partial pivoting.
+//   - m > n: least squares solution that minimizes the 2-norm of A*X - B, via QR.
+//   - m < n: minimum norm solution of the underdetermined system, via QR of A^T.
+//
+// A square A is tried with Cholesky if it is exactly symmetric, falling back
+// to LU if the factorization shows it is not positive definite.
+//
+// If the receiver is not initialized it is allocated automatically.
+// Solve returns a ConditionError holding the estimated reciprocal condition number
+// if A is singular or too ill-conditioned for the result to be trusted. The
+// estimate is computed with Hager's method on the factorization: of A for LU
+// and Cholesky, and of the triangular factor R for QR, which is within a factor
+// of min(m,n) of the reciprocal 2-norm condition number of A.
+func (X *DenseM) Solve(A, B Matrix) error {
+	m, n := A.Dims()
+	br, bc := B.Dims()
+	if br != m {
+		panic(ErrDim)
+	}
+	if X.data == nil {
+		*X = *NewDenseMatrix(n, bc, nil)
+	}
+	if r, c := X.Dims(); r != n || c != bc {
+		panic(ErrDim)
+	}
+	solve, err := factorSolve(A)
+	if err != nil {
+		return err
+	}
+	b := NewDenseVector(m, nil)
+	for j := 0; j < bc; j++ {
+		b.DoSetVec(func(i int, _ float64) float64 { return B.At(i, j) })
+		err = solve(X.ColView(j), b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SolveVec solves the linear system A*x = b and stores the result in the receiver.
+// The method is chosen from the shape and structure of A as described in DenseM.Solve.
+// If the receiver is not initialized it is allocated automatically.
+// SolveVec returns a ConditionError holding the estimated reciprocal condition number
+// if A is singular or too ill-conditioned for the result to be trusted.
+func (x *DenseV) SolveVec(A Matrix, b Vector) error {
+	m, n := A.Dims()
+	if b.Len() != m {
+		panic(ErrDim)
+	}
+	if x.data == nil {
+		*x = *NewDenseVector(n, nil)
+	}
+	if x.Len() != n {
+		panic(ErrDim)
+	}
+	solve, err := factorSolve(A)
+	if err != nil {
+		return err
+	}
+	var work DenseV
+	work.CopyVec(b)
+	return solve(x, &work)
+}
+
+// factorSolve factorizes A with the method chosen from its shape and structure
+// and returns a function that solves for a single right hand side with the
+// factorization.
+func factorSolve(A Matrix) (solve func(dst *DenseV, b Vector) error, err error) {
+	m, n := A.Dims()
+	var rcond float64
+	switch {
+	case m == n && isSymmetric(A):
+		var ch Cholesky
+		if ch.Factorize(A) {
+			rcond = ch.rcond(Norm(A, 1))
+			solve = ch.SolveVec
+			break
+		}
+		// Symmetric but not positive definite.
+		fallthrough
+	case m == n:
+		var lu LU
+		lu.Factorize(A)
+		rcond = lu.rcond()
+		solve = func(dst *DenseV, b Vector) error { return lu.SolveVec(dst, false, b) }
+	case m > n:
+		var qr QR
+		qr.Factorize(A)
+		rcond = qr.rcond()
+		solve = qr.SolveLeastSquares
+	default:
+		var qr QR
+		qr.Factorize(T(A))
+		rcond = qr.rcond()
+		solve = qr.solveMinNorm
+	}
+	if rcond < epsilon || math.IsNaN(rcond) {
+		return nil, ConditionError(rcond)
+	}
+	return solve, nil
+}
+
+// isSymmetric reports whether the square matrix A is exactly symmetric.
+func isSymmetric(A Matrix) bool {
+	n, _ := A.Dims()
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			if A.At(i, j) != A.At(j, i) {
+				return false
+			}
+		}
+	}
+	return true
+}
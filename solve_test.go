@@ -0,0 +1,119 @@
+package lap
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestSolveVec(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{{4, 4}, {7, 3}, {3, 7}} {
+		m, n := dims[0], dims[1]
+		A := NewDenseMatrix(m, n, randomSlice(rng, m*n))
+		want := NewDenseVector(n, randomSlice(rng, n))
+		var b, x DenseV
+		b.MulVec(A, want)
+		err := x.SolveVec(A, &b)
+		if err != nil {
+			t.Fatalf("%dx%d: %v", m, n, err)
+		}
+		var Ax DenseV
+		Ax.MulVec(A, &x)
+		if !vectorEqualTol(&Ax, &b, 1e-10) {
+			t.Errorf("%dx%d: A*x != b", m, n)
+		}
+		if m >= n && !vectorEqualTol(&x, want, 1e-10) {
+			t.Errorf("%dx%d: got %v, want %v", m, n, x.data, want.data)
+		}
+		if m < n {
+			// The minimum norm solution lies in the row space of A.
+			var svd SVD
+			svd.Factorize(A, SVDThin)
+			var xp DenseV
+			svd.SolveVec(&xp, &b, svd.Rank(0))
+			if !vectorEqualTol(&x, &xp, 1e-10) {
+				t.Errorf("%dx%d: solution is not minimum norm", m, n)
+			}
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	const n = 5
+	A := NewDenseMatrix(n, n, randomSlice(rng, n*n))
+	B := NewDenseMatrix(n, 3, randomSlice(rng, 3*n))
+	var X DenseM
+	err := X.Solve(A, B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var AX DenseM
+	AX.Mul(A, &X)
+	if !matrixEqualTol(&AX, B, 1e-10) {
+		t.Error("A*X != B")
+	}
+	// Solving in place overwrites B with the solution.
+	err = B.Solve(A, B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matrixEqualTol(B, &X, 1e-14) {
+		t.Error("in place solve differs")
+	}
+
+	singular := NewDenseMatrix(2, 2, []float64{
+		1, 2,
+		2, 4,
+	})
+	var x DenseV
+	err = x.SolveVec(singular, NewDenseVector(2, []float64{1, 1}))
+	var cerr ConditionError
+	if !errors.As(err, &cerr) || !errors.Is(err, ErrSingular) {
+		t.Errorf("expected ConditionError, got %v", err)
+	}
+}
+
+func TestSolveStructure(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	const n = 6
+	spd := randomSPD(rng, n)
+	// Symmetric but indefinite, so Cholesky fails and LU is used.
+	indefinite := NewDenseMatrix(n, n, nil)
+	indefinite.DoSet(func(i, j int, _ float64) float64 {
+		if i+j == n-1 {
+			return 1
+		}
+		return 0
+	})
+	for _, A := range []*DenseM{spd, indefinite} {
+		want := NewDenseVector(n, randomSlice(rng, n))
+		var b, x DenseV
+		b.MulVec(A, want)
+		if err := x.SolveVec(A, &b); err != nil {
+			t.Fatal(err)
+		}
+		if !vectorEqualTol(&x, want, 1e-10) {
+			t.Errorf("got %v, want %v", x.data, want.data)
+		}
+	}
+
+	// R has a unit diagonal but is very ill-conditioned, which the
+	// condition estimate must detect for both least squares and
+	// minimum norm problems.
+	tall := NewDenseMatrix(3, 2, []float64{
+		1, -1e20,
+		0, 1,
+		0, 0,
+	})
+	for _, A := range []Matrix{tall, T(tall)} {
+		m, _ := A.Dims()
+		var x DenseV
+		err := x.SolveVec(A, NewDenseVector(m, nil))
+		var cerr ConditionError
+		if !errors.As(err, &cerr) || cerr > 1e-30 {
+			t.Errorf("expected ConditionError for ill-conditioned A, got %v", err)
+		}
+	}
+}
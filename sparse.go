@@ -157,6 +157,27 @@ func (s *Sparse) DoNonZero(fn func(i, j int, v float64)) {
 	}
 }
 
+// MulVecTo computes A*x and stores the result in dst. If dst is not
+// initialized it is allocated automatically.
+func (s *Sparse) MulVecTo(dst *DenseV, x Vector) {
+	if x.Len() != s.c {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(s.r, nil)
+	} else if aliasedData(dst, x) {
+		panic(ErrAliasedData)
+	}
+	if dst.Len() != s.r {
+		panic(ErrDim)
+	}
+	dst.DoSetVec(func(int, float64) float64 { return 0 })
+	for k, v := range s.m {
+		i := k[0]
+		dst.SetVec(i, dst.AtVec(i)+v*x.AtVec(k[1]))
+	}
+}
+
 // CountNonZero returns number of non-zero elements in sparse matrix.
 func (s *Sparse) CountNonZero() int { return len(s.m) }
 
@@ -0,0 +1,233 @@
+package lap
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// SpyOption is a functional option for rendering the sparsity pattern of a matrix.
+type SpyOption func(*spyConfig)
+
+type spyConfig struct {
+	tol        float64
+	rows, cols int
+	unicode    bool
+}
+
+// SpyTolerance sets the threshold at or below which the absolute value of an
+// element is considered zero. The default is 0 so only exact zeros are blank.
+func SpyTolerance(tol float64) SpyOption {
+	return func(c *spyConfig) { c.tol = tol }
+}
+
+// SpySize sets the size of the rendering. For text output rows and cols are the
+// maximum number of lines and characters per line, which default to 32 and 64.
+// For image output they are the height and width in pixels, which default to the
+// dimensions of the matrix scaled down to at most 512 pixels.
+func SpySize(rows, cols int) SpyOption {
+	return func(c *spyConfig) { c.rows, c.cols = rows, cols }
+}
+
+// SpyUnicode makes Spy render with Unicode braille characters, each of which
+// shows a 4x2 block of cells, instead of an ASCII density ramp.
+func SpyUnicode() SpyOption {
+	return func(c *spyConfig) { c.unicode = true }
+}
+
+// spyRamp holds the ASCII characters used for increasing density of non-zeros.
+const spyRamp = ".:-=+*#%@"
+
+// Spy writes a text rendering of the sparsity pattern of A to w. When A has
+// more rows or columns than fit in the output, each character summarizes a
+// block of elements: in ASCII mode the character shows the fraction of
+// non-zero elements in the block on the ramp " .:-=+*#%@", and in Unicode
+// mode each braille dot is set if its block has any non-zero element.
+// The first line gives the dimensions and number of non-zero elements.
+//
+// Spy uses the DoNonZero method of A if it has one, such as *Sparse, *CSR and
+// *CSC do, so only the non-zero elements are visited.
+func Spy(w io.Writer, A Matrix, opts ...SpyOption) error {
+	cfg := newSpyConfig(opts, 32, 64)
+	r, c := A.Dims()
+	bw := bufio.NewWriter(w)
+	var nnz int
+	if cfg.unicode {
+		g := newSpyGrid(r, c, 4*cfg.rows, 2*cfg.cols)
+		nnz = g.accumulate(A, cfg.tol)
+		fmt.Fprintf(bw, "Dims(%d, %d), nnz %d\n", r, c, nnz)
+		lines, chars := (g.rows+3)/4, (g.cols+1)/2
+		bw.WriteString("┌" + strings.Repeat("─", chars) + "┐\n")
+		// Bit of each dot in a braille character indexed by [row][col].
+		bits := [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+		for l := 0; l < lines; l++ {
+			bw.WriteString("│")
+			for k := 0; k < chars; k++ {
+				ch := rune(0x2800)
+				for di := 0; di < 4; di++ {
+					for dj := 0; dj < 2; dj++ {
+						i, j := 4*l+di, 2*k+dj
+						if i < g.rows && j < g.cols && g.count[i*g.cols+j] > 0 {
+							ch |= bits[di][dj]
+						}
+					}
+				}
+				bw.WriteRune(ch)
+			}
+			bw.WriteString("│\n")
+		}
+		bw.WriteString("└" + strings.Repeat("─", chars) + "┘\n")
+		return bw.Flush()
+	}
+
+	g := newSpyGrid(r, c, cfg.rows, cfg.cols)
+	nnz = g.accumulate(A, cfg.tol)
+	fmt.Fprintf(bw, "Dims(%d, %d), nnz %d\n", r, c, nnz)
+	border := "+" + strings.Repeat("-", g.cols) + "+\n"
+	bw.WriteString(border)
+	for i := 0; i < g.rows; i++ {
+		bw.WriteByte('|')
+		for j := 0; j < g.cols; j++ {
+			d := g.density(i, j)
+			if d == 0 {
+				bw.WriteByte(' ')
+				continue
+			}
+			k := int(math.Ceil(d*float64(len(spyRamp)))) - 1
+			bw.WriteByte(spyRamp[max(0, min(k, len(spyRamp)-1))])
+		}
+		bw.WriteString("|\n")
+	}
+	bw.WriteString(border)
+	return bw.Flush()
+}
+
+// SpyImage returns a grayscale image of the sparsity pattern of A. Zero elements
+// are white and blocks containing non-zero elements are gray to black with
+// increasing density of non-zeros. Only the SpyTolerance and SpySize options
+// are used. Like Spy, SpyImage uses the DoNonZero method of A if it has one.
+func SpyImage(A Matrix, opts ...SpyOption) *image.Gray {
+	r, c := A.Dims()
+	height, width := max(r, 1), max(c, 1)
+	if s := max(r, c); s > 512 {
+		height = max(1, r*512/s)
+		width = max(1, c*512/s)
+	}
+	cfg := newSpyConfig(opts, height, width)
+	g := newSpyGrid(r, c, cfg.rows, cfg.cols)
+	g.accumulate(A, cfg.tol)
+	img := image.NewGray(image.Rect(0, 0, cfg.cols, cfg.rows))
+	for y := 0; y < cfg.rows; y++ {
+		gi := y * g.rows / cfg.rows
+		for x := 0; x < cfg.cols; x++ {
+			gj := x * g.cols / cfg.cols
+			v := uint8(255)
+			if d := g.density(gi, gj); d > 0 {
+				v = uint8(192 * (1 - d))
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+// WriteSpyPGM writes the image returned by SpyImage to w in binary PGM format.
+func WriteSpyPGM(w io.Writer, A Matrix, opts ...SpyOption) error {
+	img := SpyImage(A, opts...)
+	b := img.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P5\n%d %d\n255\n", b.Dx(), b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		bw.Write(img.Pix[y*img.Stride : y*img.Stride+b.Dx()])
+	}
+	return bw.Flush()
+}
+
+// WriteSpyPNG writes the image returned by SpyImage to w in PNG format.
+func WriteSpyPNG(w io.Writer, A Matrix, opts ...SpyOption) error {
+	return png.Encode(w, SpyImage(A, opts...))
+}
+
+func newSpyConfig(opts []SpyOption, rows, cols int) spyConfig {
+	cfg := spyConfig{rows: rows, cols: cols}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.rows <= 0 || cfg.cols <= 0 {
+		panic("spy size must be positive")
+	}
+	return cfg
+}
+
+// spyGrid counts the non-zero elements of an rxc matrix in a grid of blocks.
+// Block (bi, bj) holds the elements (i, j) with i*rows/r == bi and j*cols/c == bj.
+type spyGrid struct {
+	r, c       int
+	rows, cols int
+	count      []int
+	// rowSpan and colSpan hold the number of matrix rows and columns
+	// in each block row and column.
+	rowSpan, colSpan []int
+}
+
+// newSpyGrid returns a grid of at most rows x cols blocks for an rxc matrix.
+func newSpyGrid(r, c, rows, cols int) *spyGrid {
+	rows, cols = min(rows, r), min(cols, c)
+	g := &spyGrid{
+		r: r, c: c,
+		rows: rows, cols: cols,
+		count:   make([]int, rows*cols),
+		rowSpan: make([]int, rows),
+		colSpan: make([]int, cols),
+	}
+	spans(g.rowSpan, r)
+	spans(g.colSpan, c)
+	return g
+}
+
+// spans sets span[b] to the number of indices i in [0, n) with i*len(span)/n == b.
+// Block b starts at index ceil(b*n/len(span)), so the indices need not be visited.
+func spans(span []int, n int) {
+	m := len(span)
+	start := func(b int) int { return (b*n + m - 1) / m }
+	for b := range span {
+		span[b] = start(b+1) - start(b)
+	}
+}
+
+// accumulate counts the elements of A with absolute value above tol and
+// returns their number.
+func (g *spyGrid) accumulate(A Matrix, tol float64) (nnz int) {
+	add := func(i, j int, v float64) {
+		if math.Abs(v) <= tol {
+			return
+		}
+		nnz++
+		g.count[(i*g.rows/g.r)*g.cols+j*g.cols/g.c]++
+	}
+	if nz, ok := A.(interface {
+		DoNonZero(fn func(i, j int, v float64))
+	}); ok {
+		nz.DoNonZero(add)
+		return nnz
+	}
+	for i := 0; i < g.r; i++ {
+		for j := 0; j < g.c; j++ {
+			add(i, j, A.At(i, j))
+		}
+	}
+	return nnz
+}
+
+// density returns the fraction of non-zero elements in block (i, j).
+func (g *spyGrid) density(i, j int) float64 {
+	if len(g.count) == 0 {
+		return 0
+	}
+	return float64(g.count[i*g.cols+j]) / (float64(g.rowSpan[i]) * float64(g.colSpan[j]))
+}
@@ -0,0 +1,122 @@
+package lap
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// nonZeroOnly is a huge matrix that may only be accessed through DoNonZero.
+type nonZeroOnly struct{ n int }
+
+func (m nonZeroOnly) Dims() (int, int)    { return m.n, m.n }
+func (m nonZeroOnly) At(i, j int) float64 { panic("At called") }
+func (m nonZeroOnly) DoNonZero(fn func(i, j int, v float64)) {
+	for k := 0; k < m.n; k += m.n / 4 {
+		fn(k, k, 1)
+	}
+}
+
+func TestSpy(t *testing.T) {
+	A := NewDenseMatrix(3, 4, []float64{
+		1, 0, 0, 1e-12,
+		0, 2, 0, 0,
+		3, 0, 0, 4,
+	})
+	var buf bytes.Buffer
+	if err := Spy(&buf, A, SpyTolerance(1e-9)); err != nil {
+		t.Fatal(err)
+	}
+	want := `Dims(3, 4), nnz 4
++----+
+|@   |
+| @  |
+|@  @|
++----+
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// Downsampling 8x8 into 2x2 blocks of 4x4 elements.
+	s := NewSparse(8, 8)
+	for i := 0; i < 8; i++ {
+		s.Set(i, i, 1)
+	}
+	s.Set(0, 7, 1)
+	for _, m := range []Matrix{s, NewCSR(s)} {
+		buf.Reset()
+		Spy(&buf, m, SpySize(2, 2))
+		want = `Dims(8, 8), nnz 9
++--+
+|-.|
+| -|
++--+
+`
+		if buf.String() != want {
+			t.Errorf("%T: got\n%s\nwant\n%s", m, buf.String(), want)
+		}
+	}
+
+	buf.Reset()
+	Spy(&buf, s, SpyUnicode())
+	want = "Dims(8, 8), nnz 9\n┌────┐\n│⠑⢄⠀⠈│\n│⠀⠀⠑⢄│\n└────┘\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Spy(&buf, nonZeroOnly{n: 1 << 40}, SpySize(4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "Dims(1099511627776, 1099511627776), nnz 4\n+----+\n|.   |\n| .  |") {
+		t.Errorf("unexpected output for DoNonZero matrix:\n%s", buf.String())
+	}
+}
+
+func TestSpyImage(t *testing.T) {
+	s := NewSparse(4, 6)
+	s.Set(0, 0, 1)
+	s.Set(3, 5, -1)
+	img := SpyImage(s)
+	if b := img.Bounds(); b.Dx() != 6 || b.Dy() != 4 {
+		t.Fatalf("unexpected bounds %v", b)
+	}
+	if img.GrayAt(0, 0).Y != 0 || img.GrayAt(5, 3).Y != 0 || img.GrayAt(1, 0).Y != 255 {
+		t.Error("unexpected pixel values")
+	}
+
+	img = SpyImage(nonZeroOnly{n: 1 << 40}, SpySize(8, 8))
+	if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 8 {
+		t.Fatalf("unexpected bounds %v", b)
+	}
+	if img.GrayAt(2, 2).Y == 255 || img.GrayAt(3, 3).Y != 255 {
+		t.Error("unexpected pixel values for DoNonZero matrix")
+	}
+
+	// Upsampling a small matrix.
+	img = SpyImage(Eye(2), SpySize(4, 4))
+	if img.GrayAt(1, 1).Y != 0 || img.GrayAt(2, 1).Y != 255 || img.GrayAt(3, 3).Y != 0 {
+		t.Error("unexpected pixel values for upsampled matrix")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSpyPGM(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "P5\n6 4\n255\n") || buf.Len() != len("P5\n6 4\n255\n")+24 {
+		t.Errorf("unexpected PGM output %q", buf.String())
+	}
+	buf.Reset()
+	if err := WriteSpyPNG(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := decoded.Bounds(); b.Dx() != 6 || b.Dy() != 4 {
+		t.Errorf("unexpected PNG bounds %v", b)
+	}
+}
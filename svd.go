@@ -0,0 +1,591 @@
+package lap
+
+import (
+	"math"
+	"sort"
+)
+
+// SVDKind specifies which singular vectors are computed during an SVD factorization.
+type SVDKind int
+
+const (
+	// SVDNone specifies that no singular vectors be computed.
+	SVDNone SVDKind = iota
+	// SVDThin specifies that the first min(m,n) left and right singular vectors
+	// of an mxn matrix be computed.
+	SVDThin
+	// SVDFull specifies that all m left and n right singular vectors
+	// of an mxn matrix be computed.
+	SVDFull
+)
+
+// SVD is a type for creating and using the singular value decomposition of
+// an mxn matrix
+//
+//	A = U * Σ * V^T
+//
+// where U and V have orthonormal columns and Σ is diagonal with
+// non-negative singular values in descending order.
+//
+// The decomposition is computed with one-sided Jacobi rotations, which
+// yield singular values with high relative accuracy.
+type SVD struct {
+	kind SVDKind
+	m, n int
+	s    []float64
+	u, v DenseM
+}
+
+// Factorize computes the singular value decomposition of A and stores the
+// result in the receiver. kind specifies which singular vectors are computed.
+// Factorize returns false if the Jacobi iteration failed to converge.
+func (svd *SVD) Factorize(A Matrix, kind SVDKind) (ok bool) {
+	m, n := A.Dims()
+	s, u, v, ok := svdFactorize[float64](A, kind)
+	svd.m, svd.n = m, n
+	svd.kind = kind
+	svd.s = s
+	svd.u, svd.v = DenseM{}, DenseM{}
+	if kind == SVDNone {
+		return ok
+	}
+	wr, k := max(m, n), min(m, n)
+	ucols := k
+	if kind == SVDFull {
+		ucols = wr
+	}
+	U := NewDenseMatrix(wr, ucols, u)
+	V := NewDenseMatrix(k, k, v)
+	if m < n {
+		svd.u, svd.v = *V, *U
+	} else {
+		svd.u, svd.v = *U, *V
+	}
+	return ok
+}
+
+// svdFactorize computes the singular value decomposition of the mxn matrix A
+// with one-sided Jacobi rotations. It returns the singular values in descending
+// order and whether the iteration converged. Unless kind is SVDNone it also
+// returns the singular vectors of the working matrix W, which is A if m >= n
+// and A^T otherwise: u holds the left singular vectors of W in the columns of a
+// max(m,n) x k matrix, where k is min(m,n) for SVDThin and max(m,n) for SVDFull,
+// and v holds the right singular vectors of W in the columns of a min(m,n) x
+// min(m,n) matrix, both stored contiguously in row major order.
+// svdFactorize is shared by SVD and SVDOf.
+func svdFactorize[T Float](A MatrixOf[T], kind SVDKind) (s, u, v []T, ok bool) {
+	const maxSweeps = 60
+	// The tolerance is 1e-15 for float64 and scaled to the precision of T.
+	tol := T(1e-15 * epsilonOf[T]() / epsilon)
+	m, n := A.Dims()
+	// Work on A^T when A is wide so that the working matrix always has at
+	// least as many rows as columns.
+	wr, wc := max(m, n), min(m, n)
+	w := make([]T, wr*wc)
+	for i := 0; i < wr; i++ {
+		for j := 0; j < wc; j++ {
+			if m < n {
+				w[i*wc+j] = A.At(j, i)
+			} else {
+				w[i*wc+j] = A.At(i, j)
+			}
+		}
+	}
+	wantVec := kind != SVDNone
+	var V []T
+	if wantVec {
+		V = make([]T, wc*wc)
+		for i := 0; i < wc; i++ {
+			V[i*wc+i] = 1
+		}
+	}
+	for sweep := 0; sweep < maxSweeps && !ok; sweep++ {
+		rots := 0
+		for p := 0; p < wc; p++ {
+			for q := p + 1; q < wc; q++ {
+				var alpha, beta, gamma T
+				for i := 0; i < wr; i++ {
+					wp, wq := w[i*wc+p], w[i*wc+q]
+					alpha += wp * wp
+					beta += wp * wq
+					gamma += wq * wq
+				}
+				if beta == 0 || absOf(beta) <= tol*sqrtOf(alpha*gamma) {
+					continue
+				}
+				rots++
+				c, s, _ := jacobiRotation(alpha, beta, gamma)
+				rotateCols(w, wc, wr, p, q, c, s)
+				if wantVec {
+					rotateCols(V, wc, wc, p, q, c, s)
+				}
+			}
+		}
+		ok = rots == 0
+	}
+
+	// The singular values are the column norms of the rotated matrix.
+	sigma := make([]T, wc)
+	for j := range sigma {
+		var sum T
+		for i := 0; i < wr; i++ {
+			sum += w[i*wc+j] * w[i*wc+j]
+		}
+		sigma[j] = sqrtOf(sum)
+	}
+	order := make([]int, wc)
+	irange(order, 0, 1)
+	sort.SliceStable(order, func(i, j int) bool { return sigma[order[i]] > sigma[order[j]] })
+	s = make([]T, wc)
+	for i, idx := range order {
+		s[i] = sigma[idx]
+	}
+	if !wantVec {
+		return s, nil, nil, ok
+	}
+
+	ucols := wc
+	if kind == SVDFull {
+		ucols = wr
+	}
+	u = make([]T, wr*ucols)
+	v = make([]T, wc*wc)
+	eps := epsilonOf[T]()
+	var rank int
+	for i, idx := range order {
+		for r := 0; r < wc; r++ {
+			v[r*wc+i] = V[r*wc+idx]
+		}
+		if float64(s[i]) <= float64(wr)*eps*float64(s[0]) {
+			continue
+		}
+		rank++
+		for r := 0; r < wr; r++ {
+			u[r*ucols+i] = w[r*wc+idx] / s[i]
+		}
+	}
+	completeOrthonormal(u, ucols, wr, ucols, rank)
+	return s, u, v, ok
+}
+
+// rotateCols applies the plane rotation [c s; -s c] to columns p and q of the
+// matrix a with r rows stored row major with the given stride.
+func rotateCols[T Float](a []T, stride, r, p, q int, c, s T) {
+	for i := 0; i < r; i++ {
+		idx := i * stride
+		ap, aq := a[idx+p], a[idx+q]
+		a[idx+p] = c*ap - s*aq
+		a[idx+q] = s*ap + c*aq
+	}
+}
+
+// completeOrthonormal fills columns k onwards of the mxc matrix q, stored row
+// major with the given stride, so that all columns are orthonormal, given that
+// the first k columns already are.
+func completeOrthonormal[T Float](q []T, stride, m, c, k int) {
+	w := make([]T, m)
+	for j := k; j < c; j++ {
+		// Pick the canonical basis vector with the largest component
+		// orthogonal to the existing columns.
+		var best T = -1
+		var ibest int
+		for i := 0; i < m; i++ {
+			var res T = 1
+			for l := 0; l < j; l++ {
+				v := q[i*stride+l]
+				res -= v * v
+			}
+			if res > best {
+				best, ibest = res, i
+			}
+		}
+		for i := range w {
+			w[i] = 0
+		}
+		w[ibest] = 1
+		// Orthogonalize twice for numerical stability.
+		for pass := 0; pass < 2; pass++ {
+			for l := 0; l < j; l++ {
+				var d T
+				for i := 0; i < m; i++ {
+					d += q[i*stride+l] * w[i]
+				}
+				for i := 0; i < m; i++ {
+					w[i] -= d * q[i*stride+l]
+				}
+			}
+		}
+		var norm T
+		for _, v := range w {
+			norm += v * v
+		}
+		norm = sqrtOf(norm)
+		for i := 0; i < m; i++ {
+			q[i*stride+j] = w[i] / norm
+		}
+	}
+}
+
+// Kind returns the SVDKind of the decomposition.
+func (svd *SVD) Kind() SVDKind { return svd.kind }
+
+// Values returns the singular values of the factorized matrix in descending order.
+// If dst is nil a new slice is allocated, otherwise dst must have length min(m,n).
+func (svd *SVD) Values(dst []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, len(svd.s))
+	}
+	if len(dst) != len(svd.s) {
+		panic(ErrDim)
+	}
+	copy(dst, svd.s)
+	return dst
+}
+
+// UTo stores the left singular vectors of the factorized mxn matrix in dst.
+// dst is mxmin(m,n) for SVDThin and mxm for SVDFull. If dst is not initialized
+// it is allocated automatically. UTo panics if the singular vectors were not computed.
+func (svd *SVD) UTo(dst *DenseM) {
+	if svd.kind == SVDNone {
+		panic("singular vectors not computed")
+	}
+	svd.vecsTo(dst, &svd.u)
+}
+
+// VTo stores the right singular vectors of the factorized mxn matrix in dst.
+// dst is nxmin(m,n) for SVDThin and nxn for SVDFull. If dst is not initialized
+// it is allocated automatically. VTo panics if the singular vectors were not computed.
+func (svd *SVD) VTo(dst *DenseM) {
+	if svd.kind == SVDNone {
+		panic("singular vectors not computed")
+	}
+	svd.vecsTo(dst, &svd.v)
+}
+
+func (svd *SVD) vecsTo(dst, src *DenseM) {
+	r, _ := src.Dims()
+	c := len(svd.s)
+	if svd.kind == SVDFull {
+		c = r
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(r, c, nil)
+	}
+	if dr, dc := dst.Dims(); dr != r || dc != c {
+		panic(ErrDim)
+	}
+	dst.Copy(src.Slice(0, r, 0, c))
+}
+
+// Rank returns the number of singular values greater than tol times the
+// largest singular value. If tol is not positive a default tolerance of
+// max(m,n) times the machine epsilon is used.
+func (svd *SVD) Rank(tol float64) int {
+	return svdRank(svd.s, svd.m, svd.n, tol)
+}
+
+// Cond returns the 2-norm condition number of the factorized matrix, the ratio
+// of the largest to the smallest singular value.
+func (svd *SVD) Cond() float64 {
+	return svdCond(svd.s)
+}
+
+// svdRank returns the rank of the mxn matrix with singular values s as
+// documented by SVD.Rank. svdRank is shared by SVD and SVDOf.
+func svdRank[T Float](s []T, m, n int, tol float64) int {
+	if len(s) == 0 {
+		return 0
+	}
+	if tol <= 0 {
+		tol = float64(max(m, n)) * epsilonOf[T]()
+	}
+	var rank int
+	for _, v := range s {
+		if float64(v) > tol*float64(s[0]) {
+			rank++
+		}
+	}
+	return rank
+}
+
+// svdCond returns the 2-norm condition number of the matrix with singular
+// values s in descending order. svdCond is shared by SVD and SVDOf.
+func svdCond[T Float](s []T) float64 {
+	k := len(s)
+	if k == 0 {
+		return 1
+	}
+	if s[k-1] == 0 {
+		return math.Inf(1)
+	}
+	return float64(s[0]) / float64(s[k-1])
+}
+
+// SolveVec finds the minimum norm x that minimizes the 2-norm of A*x - b
+// using only the first rank singular values of A and stores it in dst.
+// Truncating the rank regularizes rank-deficient and ill-conditioned systems.
+// If dst is not initialized it is allocated automatically.
+// SolveVec panics if the singular vectors were not computed.
+func (svd *SVD) SolveVec(dst *DenseV, b Vector, rank int) {
+	if b.Len() != svd.m {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVector(svd.n, nil)
+	}
+	if dst.Len() != svd.n {
+		panic(ErrDim)
+	}
+	svd.checkRank(rank)
+	work := NewDenseVector(svd.n, nil)
+	for i := 0; i < rank; i++ {
+		f := Dot(svd.u.ColView(i), b) / svd.s[i]
+		vi := svd.v.ColView(i)
+		work.DoSetVec(func(j int, x float64) float64 { return x + f*vi.AtVec(j) })
+	}
+	dst.CopyVec(work)
+}
+
+// Solve finds the minimum norm X that minimizes the Frobenius norm of A*X - B
+// using only the first rank singular values of A and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// Solve panics if the singular vectors were not computed.
+func (svd *SVD) Solve(dst *DenseM, B Matrix, rank int) {
+	br, bc := B.Dims()
+	if br != svd.m {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(svd.n, bc, nil)
+	}
+	if r, c := dst.Dims(); r != svd.n || c != bc {
+		panic(ErrDim)
+	}
+	col := NewDenseVector(br, nil)
+	for j := 0; j < bc; j++ {
+		col.DoSetVec(func(i int, _ float64) float64 { return B.At(i, j) })
+		svd.SolveVec(dst.ColView(j), col, rank)
+	}
+}
+
+// PseudoInverse computes the Moore-Penrose pseudo inverse of the factorized
+// matrix using only the first rank singular values and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// PseudoInverse panics if the singular vectors were not computed.
+func (svd *SVD) PseudoInverse(dst *DenseM, rank int) {
+	if dst.data == nil {
+		*dst = *NewDenseMatrix(svd.n, svd.m, nil)
+	}
+	if r, c := dst.Dims(); r != svd.n || c != svd.m {
+		panic(ErrDim)
+	}
+	svd.checkRank(rank)
+	dst.DoSet(func(i, j int, _ float64) float64 {
+		var sum float64
+		for k := 0; k < rank; k++ {
+			sum += svd.v.At(i, k) * svd.u.At(j, k) / svd.s[k]
+		}
+		return sum
+	})
+}
+
+func (svd *SVD) checkRank(rank int) {
+	if svd.kind == SVDNone {
+		panic("singular vectors not computed")
+	}
+	if rank < 0 || rank > len(svd.s) {
+		panic("bad rank")
+	}
+}
+
+// SVDOf is the generic counterpart of SVD for matrices with elements of type T.
+// It shares its implementation with SVD.
+type SVDOf[T Float] struct {
+	kind SVDKind
+	m, n int
+	s    []T
+	u, v DenseMOf[T]
+}
+
+// Factorize computes the singular value decomposition of A and stores the
+// result in the receiver. kind specifies which singular vectors are computed.
+// Factorize returns false if the Jacobi iteration failed to converge.
+func (svd *SVDOf[T]) Factorize(A MatrixOf[T], kind SVDKind) (ok bool) {
+	m, n := A.Dims()
+	s, u, v, ok := svdFactorize(A, kind)
+	svd.m, svd.n = m, n
+	svd.kind = kind
+	svd.s = s
+	svd.u, svd.v = DenseMOf[T]{}, DenseMOf[T]{}
+	if kind == SVDNone {
+		return ok
+	}
+	wr, k := max(m, n), min(m, n)
+	ucols := k
+	if kind == SVDFull {
+		ucols = wr
+	}
+	U := NewDenseMatrixOf(wr, ucols, u)
+	V := NewDenseMatrixOf(k, k, v)
+	if m < n {
+		svd.u, svd.v = *V, *U
+	} else {
+		svd.u, svd.v = *U, *V
+	}
+	return ok
+}
+
+// Kind returns the SVDKind of the decomposition.
+func (svd *SVDOf[T]) Kind() SVDKind { return svd.kind }
+
+// Values returns the singular values of the factorized matrix in descending order.
+// If dst is nil a new slice is allocated, otherwise dst must have length min(m,n).
+func (svd *SVDOf[T]) Values(dst []T) []T {
+	if dst == nil {
+		dst = make([]T, len(svd.s))
+	}
+	if len(dst) != len(svd.s) {
+		panic(ErrDim)
+	}
+	copy(dst, svd.s)
+	return dst
+}
+
+// UTo stores the left singular vectors of the factorized mxn matrix in dst.
+// dst is mxmin(m,n) for SVDThin and mxm for SVDFull. If dst is not initialized
+// it is allocated automatically. UTo panics if the singular vectors were not computed.
+func (svd *SVDOf[T]) UTo(dst *DenseMOf[T]) {
+	if svd.kind == SVDNone {
+		panic("singular vectors not computed")
+	}
+	svd.vecsTo(dst, &svd.u)
+}
+
+// VTo stores the right singular vectors of the factorized mxn matrix in dst.
+// dst is nxmin(m,n) for SVDThin and nxn for SVDFull. If dst is not initialized
+// it is allocated automatically. VTo panics if the singular vectors were not computed.
+func (svd *SVDOf[T]) VTo(dst *DenseMOf[T]) {
+	if svd.kind == SVDNone {
+		panic("singular vectors not computed")
+	}
+	svd.vecsTo(dst, &svd.v)
+}
+
+func (svd *SVDOf[T]) vecsTo(dst, src *DenseMOf[T]) {
+	r, _ := src.Dims()
+	c := len(svd.s)
+	if svd.kind == SVDFull {
+		c = r
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](r, c, nil)
+	}
+	if dr, dc := dst.Dims(); dr != r || dc != c {
+		panic(ErrDim)
+	}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			dst.data[i*dst.stride+j] = src.data[i*src.stride+j]
+		}
+	}
+}
+
+// Rank returns the number of singular values greater than tol times the
+// largest singular value. If tol is not positive a default tolerance of
+// max(m,n) times the machine epsilon of T is used.
+func (svd *SVDOf[T]) Rank(tol float64) int {
+	return svdRank(svd.s, svd.m, svd.n, tol)
+}
+
+// Cond returns the 2-norm condition number of the factorized matrix, the ratio
+// of the largest to the smallest singular value.
+func (svd *SVDOf[T]) Cond() float64 {
+	return svdCond(svd.s)
+}
+
+// SolveVec finds the minimum norm x that minimizes the 2-norm of A*x - b
+// using only the first rank singular values of A and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// SolveVec panics if the singular vectors were not computed.
+func (svd *SVDOf[T]) SolveVec(dst *DenseVOf[T], b VectorOf[T], rank int) {
+	if b.Len() != svd.m {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseVectorOf[T](svd.n, nil)
+	}
+	if dst.Len() != svd.n {
+		panic(ErrDim)
+	}
+	svd.checkRank(rank)
+	work := make([]T, svd.n)
+	for i := 0; i < rank; i++ {
+		var f T
+		for r := 0; r < svd.m; r++ {
+			f += svd.u.At(r, i) * b.AtVec(r)
+		}
+		f /= svd.s[i]
+		for j := range work {
+			work[j] += f * svd.v.At(j, i)
+		}
+	}
+	for j, v := range work {
+		dst.SetVec(j, v)
+	}
+}
+
+// Solve finds the minimum norm X that minimizes the Frobenius norm of A*X - B
+// using only the first rank singular values of A and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// Solve panics if the singular vectors were not computed.
+func (svd *SVDOf[T]) Solve(dst *DenseMOf[T], B MatrixOf[T], rank int) {
+	br, bc := B.Dims()
+	if br != svd.m {
+		panic(ErrDim)
+	}
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](svd.n, bc, nil)
+	}
+	if r, c := dst.Dims(); r != svd.n || c != bc {
+		panic(ErrDim)
+	}
+	col := NewDenseVectorOf[T](br, nil)
+	for j := 0; j < bc; j++ {
+		for i := 0; i < br; i++ {
+			col.SetVec(i, B.At(i, j))
+		}
+		svd.SolveVec(dst.ColView(j), col, rank)
+	}
+}
+
+// PseudoInverse computes the Moore-Penrose pseudo inverse of the factorized
+// matrix using only the first rank singular values and stores it in dst.
+// If dst is not initialized it is allocated automatically.
+// PseudoInverse panics if the singular vectors were not computed.
+func (svd *SVDOf[T]) PseudoInverse(dst *DenseMOf[T], rank int) {
+	if dst.data == nil {
+		*dst = *NewDenseMatrixOf[T](svd.n, svd.m, nil)
+	}
+	if r, c := dst.Dims(); r != svd.n || c != svd.m {
+		panic(ErrDim)
+	}
+	svd.checkRank(rank)
+	for i := 0; i < svd.n; i++ {
+		for j := 0; j < svd.m; j++ {
+			var sum T
+			for k := 0; k < rank; k++ {
+				sum += svd.v.At(i, k) * svd.u.At(j, k) / svd.s[k]
+			}
+			dst.data[i*dst.stride+j] = sum
+		}
+	}
+}
+
+func (svd *SVDOf[T]) checkRank(rank int) {
+	if svd.kind == SVDNone {
+		panic("singular vectors not computed")
+	}
+	if rank < 0 || rank > len(svd.s) {
+		panic("bad rank")
+	}
+}
@@ -0,0 +1,100 @@
+package lap
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSVDFactorize(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, dims := range [][2]int{{3, 3}, {5, 3}, {3, 5}, {6, 1}} {
+		m, n := dims[0], dims[1]
+		A := NewDenseMatrix(m, n, randomSlice(rng, m*n))
+		for _, kind := range []SVDKind{SVDThin, SVDFull} {
+			var svd SVD
+			if !svd.Factorize(A, kind) {
+				t.Fatalf("%dx%d: SVD did not converge", m, n)
+			}
+			var U, V, UTU, VTV DenseM
+			svd.UTo(&U)
+			svd.VTo(&V)
+			s := svd.Values(nil)
+			for i := 1; i < len(s); i++ {
+				if s[i] > s[i-1] {
+					t.Errorf("%dx%d: singular values not in descending order: %v", m, n, s)
+				}
+			}
+			_, uc := U.Dims()
+			_, vc := V.Dims()
+			UTU.Mul(T(&U), &U)
+			VTV.Mul(T(&V), &V)
+			if !matrixEqualTol(&UTU, Eye(uc), 1e-12) {
+				t.Errorf("%dx%d kind=%d: U columns not orthonormal", m, n, kind)
+			}
+			if !matrixEqualTol(&VTV, Eye(vc), 1e-12) {
+				t.Errorf("%dx%d kind=%d: V columns not orthonormal", m, n, kind)
+			}
+			Sigma := NewDenseMatrix(uc, vc, nil)
+			for i, v := range s {
+				Sigma.Set(i, i, v)
+			}
+			var US, USVT DenseM
+			US.Mul(&U, Sigma)
+			USVT.Mul(&US, T(&V))
+			if !matrixEqualTol(&USVT, A, 1e-12) {
+				t.Errorf("%dx%d kind=%d: U*Σ*V^T != A", m, n, kind)
+			}
+		}
+	}
+}
+
+func TestSVDRankDeficient(t *testing.T) {
+	// Third column is the sum of the first two.
+	A := NewDenseMatrix(4, 3, []float64{
+		1, 2, 3,
+		4, 5, 9,
+		7, 8, 15,
+		1, 0, 1,
+	})
+	var svd SVD
+	if !svd.Factorize(A, SVDThin) {
+		t.Fatal("SVD did not converge")
+	}
+	rank := svd.Rank(0)
+	if rank != 2 {
+		t.Fatalf("expected rank 2, got %d", rank)
+	}
+	if cond := svd.Cond(); cond < 1e12 && !math.IsInf(cond, 1) {
+		t.Errorf("expected huge condition number, got %g", cond)
+	}
+	var pinv, APA, PAP DenseM
+	svd.PseudoInverse(&pinv, rank)
+	var AP DenseM
+	AP.Mul(A, &pinv)
+	APA.Mul(&AP, A)
+	if !matrixEqualTol(&APA, A, 1e-10) {
+		t.Error("A*pinv(A)*A != A")
+	}
+	var PA DenseM
+	PA.Mul(&pinv, A)
+	PAP.Mul(&PA, &pinv)
+	if !matrixEqualTol(&PAP, &pinv, 1e-10) {
+		t.Error("pinv(A)*A*pinv(A) != pinv(A)")
+	}
+
+	// b is in the range of A so the minimum norm solution is exact.
+	b := NewDenseVector(4, nil)
+	b.MulVec(A, NewDenseVector(3, []float64{1, 1, 0}))
+	var x, Ax DenseV
+	svd.SolveVec(&x, b, rank)
+	Ax.MulVec(A, &x)
+	if !vectorEqualTol(&Ax, b, 1e-10) {
+		t.Errorf("A*x != b: %v", Ax.data)
+	}
+	var xp DenseV
+	xp.MulVec(&pinv, b)
+	if !vectorEqualTol(&x, &xp, 1e-10) {
+		t.Error("solution differs from pseudo inverse solution")
+	}
+}
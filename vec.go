@@ -196,11 +196,22 @@ func dataHeader(m Matrix) reflect.SliceHeader {
 		return dataHeader(D.sm)
 	case Transpose:
 		return dataHeader(D.m)
+	case *CSR:
+		backingData = D.data
+	case *CSC:
+		backingData = D.data
 	case *Sparse:
 		v := reflect.ValueOf(D.m)
 		return reflect.SliceHeader{Data: v.Pointer(), Len: 8, Cap: 8} // Best we can do?
 	default:
 		panic("unknown Matrix type. Can't determine backing data " + fmt.Sprintf("%T", D))
 	}
-	return *(*reflect.SliceHeader)(unsafe.Pointer(&backingData))
+	if len(backingData) == 0 {
+		return reflect.SliceHeader{}
+	}
+	return reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(&backingData[0])),
+		Len:  len(backingData),
+		Cap:  cap(backingData),
+	}
 }